@@ -0,0 +1,70 @@
+// Copyright 2012 Daniel Connelly.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtreego
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// SearchIntersectBatch runs SearchIntersect for every rectangle in rects on
+// the given number of goroutines, or on GOMAXPROCS goroutines if workers is
+// not positive. The results for rects[i] are stored at index i. The tree
+// must not be modified until the batch is done.
+func (tree *Rtree) SearchIntersectBatch(rects []Rect, workers int) [][]Spatial {
+	results := make([][]Spatial, len(rects))
+	forEachParallel(len(rects), workers, func() func(i int) {
+		return func(i int) {
+			results[i] = tree.SearchIntersect(rects[i])
+		}
+	})
+	return results
+}
+
+// NearestNeighborsBatch runs NearestNeighbors for every point in points on the
+// given number of goroutines, or on GOMAXPROCS goroutines if workers is not
+// positive. The results for points[i] are stored at index i. The tree must not
+// be modified until the batch is done.
+func (tree *Rtree) NearestNeighborsBatch(k int, points []Point, workers int) [][]Spatial {
+	results := make([][]Spatial, len(points))
+	forEachParallel(len(points), workers, func() func(i int) {
+		// every worker reuses its own buffers for sorting the branches
+		maxBufSize := tree.MaxChildren * tree.Depth()
+		branches := make([]entry, maxBufSize)
+		branchDists := make([]float64, maxBufSize)
+		return func(i int) {
+			results[i], _ = tree.nearestNeighborsBuffered(k, points[i], nil, branches, branchDists)
+		}
+	})
+	return results
+}
+
+// forEachParallel calls a function for every index from 0 to n-1 on the given
+// number of goroutines, or on GOMAXPROCS goroutines if workers is not
+// positive. Every goroutine calls newWorker once to set up its own state and
+// obtain the function it calls for the indices it takes over.
+func forEachParallel(n, workers int, newWorker func() func(i int)) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > n {
+		workers = n
+	}
+
+	next := int64(-1)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			work := newWorker()
+			for i := int(atomic.AddInt64(&next, 1)); i < n; i = int(atomic.AddInt64(&next, 1)) {
+				work(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,51 @@
+package rtreego
+
+import (
+	"testing"
+)
+
+func TestSearchIntersectBatch(t *testing.T) {
+	things := randomRects(500, 27)
+	rt := NewTree(2, 3, 6, things...)
+
+	rects := make([]Rect, 100)
+	for i := range rects {
+		p := things[i].Bounds().p
+		rects[i] = mustRect(Point{p[0] - 3, p[1] - 3}, []float64{6, 6})
+	}
+	for _, workers := range []int{0, 1, 3, 200} {
+		results := rt.SearchIntersectBatch(rects, workers)
+		if len(results) != len(rects) {
+			t.Fatalf("expected %d results with %d workers, got %d", len(rects), workers, len(results))
+		}
+		for i, bb := range rects {
+			expected := rt.SearchIntersect(bb)
+			if len(results[i]) != len(expected) {
+				t.Errorf("SearchIntersectBatch(%v) with %d workers returned %d objects, expected %d", bb, workers, len(results[i]), len(expected))
+			}
+			ensureDisorderedSubset(t, results[i], expected)
+		}
+	}
+
+	if results := rt.SearchIntersectBatch(nil, 4); len(results) != 0 {
+		t.Errorf("expected no results for an empty batch, got %v", results)
+	}
+}
+
+func TestNearestNeighborsBatch(t *testing.T) {
+	things := randomRects(500, 28)
+	for _, opts := range []Options{{}, {Metric: Manhattan{}}} {
+		rt := NewTreeWithOptions(2, 3, 6, opts, things...)
+
+		points := make([]Point, 100)
+		for i := range points {
+			points[i] = things[i].Bounds().center()
+		}
+		for _, workers := range []int{0, 1, 3} {
+			results := rt.NearestNeighborsBatch(5, points, workers)
+			for i, p := range points {
+				ensureOrderedSubset(t, results[i], rt.NearestNeighbors(5, p))
+			}
+		}
+	}
+}
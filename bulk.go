@@ -0,0 +1,376 @@
+// Copyright 2012 Daniel Connelly.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtreego
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// load bulk loads the Rtree with more than MaxChildren objects using the
+// configured bulk-loading algorithm, replacing its contents.
+func (tree *Rtree) load(objs []Spatial) {
+	switch tree.bulkLoadMode {
+	case STRBulkLoad:
+		tree.bulkLoadSTR(objs)
+	case HilbertBulkLoad:
+		tree.bulkLoadHilbert(objs)
+	default:
+		tree.bulkLoad(objs)
+	}
+}
+
+// InsertBulk inserts many objects into the tree at once. The objects are
+// packed into a subtree using the tree's bulk-loading algorithm, which is
+// then grafted into the tree at the level matching its height. If the batch
+// is larger than the tree, the whole tree is rebuilt instead.
+//
+// Implemented following the Small-Tree-Large-Tree approach of "Fast R-tree
+// Bulk Insertion" by L. Chen, R. Choubey and E. A. Rundensteiner, 1998.
+func (tree *Rtree) InsertBulk(objs []Spatial) {
+	tree.checkWritable()
+	if len(objs) <= tree.MaxChildren {
+		for _, obj := range objs {
+			tree.Insert(obj)
+		}
+		return
+	}
+
+	if len(objs) >= tree.size {
+		all := make([]Spatial, 0, tree.size+len(objs))
+		all = tree.root.objects(all)
+		all = append(all, objs...)
+		tree.load(all)
+		return
+	}
+
+	sub := &Rtree{
+		Dim:             tree.Dim,
+		MinChildren:     tree.MinChildren,
+		MaxChildren:     tree.MaxChildren,
+		bulkLoadMode:    tree.bulkLoadMode,
+		bulkLoadWorkers: tree.bulkLoadWorkers,
+		hilbert:         tree.hilbert,
+		gen:             tree.gen,
+	}
+	// the packed subtree is never higher than the tree, which holds more
+	// objects
+	sub.load(objs)
+	tree.distanceObjects = tree.distanceObjects || sub.distanceObjects
+	tree.graft(sub.root, sub.height)
+	tree.size += len(objs)
+}
+
+// graft adds the subtree rooted at root with the given height to the tree,
+// which must be at least as high. The root of the subtree becomes an entry
+// at the level above it, unless it would underflow, in which case its
+// children are added instead. The size of the tree is not updated.
+func (tree *Rtree) graft(root *node, height int) {
+	if len(root.entries) < tree.MinChildren {
+		for _, e := range root.entries {
+			tree.insert(e, height)
+		}
+		return
+	}
+
+	if height < tree.height {
+		e := entry{bb: root.computeBoundingBox(), child: root}
+		tree.insert(e, height+1)
+		return
+	}
+
+	left, right := tree.root, root
+	if tree.insertMode == HilbertInsert &&
+		tree.hilbert.entryValue(entry{child: right}) < tree.hilbert.entryValue(entry{child: left}) {
+		left, right = right, left
+	}
+	tree.growRoot(left, right)
+}
+
+// objects appends all objects stored in the subtree of n to objs.
+func (n *node) objects(objs []Spatial) []Spatial {
+	for _, e := range n.entries {
+		if n.leaf {
+			objs = append(objs, e.obj)
+		} else {
+			objs = e.child.objects(objs)
+		}
+	}
+	return objs
+}
+
+// bulkLoadSTR bulk loads the Rtree using the Sort-Tile-Recursive algorithm.
+// The tree is built bottom-up: the entries of every level are tiled into
+// groups of MaxChildren entries, which become the nodes of the level above.
+//
+// Implemented per "STR: A Simple and Efficient Algorithm for R-Tree Packing"
+// by S. T. Leutenegger, M. A. Lopez and J. Edgington, ICDE, p. 497-506, 1997.
+func (tree *Rtree) bulkLoadSTR(objs []Spatial) {
+	pool := newWorkerPool(tree.bulkLoadWorkers)
+	tree.packBottomUp(tree.objectEntries(objs), func(entries []entry, emit func([]entry)) {
+		strTile(entries, 0, tree.Dim, tree.MaxChildren, pool, emit)
+	})
+}
+
+// bulkLoadHilbert bulk loads the Rtree by sorting the objects by the Hilbert
+// value of their centers and packing them into nodes in that order.
+//
+// Implemented per "On Packing R-trees" by I. Kamel and C. Faloutsos, CIKM,
+// p. 490-499, 1993.
+func (tree *Rtree) bulkLoadHilbert(objs []Spatial) {
+	entries := tree.objectEntries(objs)
+	curve := tree.hilbert
+	if curve == nil {
+		bb := entries[0].bb
+		for _, e := range entries[1:] {
+			bb = boundingBox(bb, e.bb)
+		}
+		curve = newHilbertCurve(tree.Dim, bb)
+	}
+	curve.sort(entries)
+
+	tree.packBottomUp(entries, func(entries []entry, emit func([]entry)) {
+		walkPartitions(tree.MaxChildren, entries, emit)
+	})
+}
+
+// packBottomUp builds the tree bottom-up from the given leaf entries. At each
+// level, tile cuts the entries into groups of at most MaxChildren entries,
+// which become the nodes of the level.
+func (tree *Rtree) packBottomUp(entries []entry, tile func(entries []entry, emit func(group []entry))) {
+	size := len(entries)
+
+	level := 1
+	for {
+		nodes := tree.packLevel(entries, level, tile)
+		if len(nodes) == 1 {
+			tree.root = nodes[0]
+			break
+		}
+
+		entries = make([]entry, len(nodes))
+		for i, n := range nodes {
+			entries[i] = entry{bb: n.computeBoundingBox(), child: n}
+		}
+		level++
+	}
+
+	tree.height = level
+	tree.size = size
+}
+
+// packLevel creates the nodes at the given level from the groups of entries
+// produced by tile.
+func (tree *Rtree) packLevel(entries []entry, level int, tile func(entries []entry, emit func(group []entry))) []*node {
+	var nodes []*node
+	tile(entries, func(group []entry) {
+		n := &node{
+			leaf:    level == 1,
+			level:   level,
+			entries: make([]entry, len(group)),
+			gen:     tree.gen,
+		}
+		copy(n.entries, group)
+		for _, e := range n.entries {
+			if e.child != nil {
+				e.child.parent = n
+			}
+		}
+		nodes = append(nodes, n)
+	})
+	return nodes
+}
+
+// strTile sorts entries by the centers of their bounding boxes one dimension
+// at a time, cutting them into slabs along each dimension, and calls emit
+// with consecutive groups of at most max entries of the last dimension. The
+// slabs are tiled in parallel on the goroutines of pool.
+func strTile(entries []entry, dim, dims, max int, pool workerPool, emit func(group []entry)) {
+	sortByCenter(dim, entries, pool)
+	if dim == dims-1 {
+		walkPartitions(max, entries, emit)
+		return
+	}
+
+	// number of nodes to be created and slabs to cut along this dimension
+	p := (len(entries) + max - 1) / max
+	s := int(math.Ceil(math.Pow(float64(p), 1/float64(dims-dim))))
+	slabSize := max * ((p + s - 1) / s)
+
+	var slabs [][]entry
+	walkPartitions(slabSize, entries, func(slab []entry) {
+		slabs = append(slabs, slab)
+	})
+
+	// collect the groups of every slab to emit them in order
+	groups := make([][][]entry, len(slabs))
+	pool.each(len(slabs), func(i int) {
+		strTile(slabs[i], dim+1, dims, max, pool, func(group []entry) {
+			groups[i] = append(groups[i], group)
+		})
+	})
+
+	for _, slabGroups := range groups {
+		for _, group := range slabGroups {
+			emit(group)
+		}
+	}
+}
+
+// sortByCenter sorts entries by the center of their bounding boxes in the
+// given dimension on the goroutines of pool. Ties keep their original order.
+func sortByCenter(dim int, entries []entry, pool workerPool) {
+	keys := make([]float64, len(entries))
+	for i, e := range entries {
+		keys[i] = e.bb.p[dim] + e.bb.q[dim]
+	}
+	sortByKeys(entries, keys, pool)
+}
+
+// minSortRun is the smallest number of entries sorted on a goroutine of its
+// own by sortByKeys.
+const minSortRun = 1 << 13
+
+// sortByKeys sorts entries by the precomputed keys, which are reordered as
+// well. Ties keep their original order, so the result is unique: runs of the
+// entries are sorted in parallel on the goroutines of pool, and then merged
+// pairwise in a fixed order, which yields the same order as a single sort.
+func sortByKeys(entries []entry, keys []float64, pool workerPool) {
+	n := len(entries)
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	s := keySorter{entries, keys, order}
+
+	runs := cap(pool) + 1
+	if most := (n + minSortRun - 1) / minSortRun; runs > most {
+		runs = most
+	}
+	if runs <= 1 {
+		sort.Sort(s)
+		return
+	}
+
+	size := (n + runs - 1) / runs
+	pool.each((n+size-1)/size, func(i int) {
+		sort.Sort(s.slice(i*size, (i+1)*size))
+	})
+
+	buf := keySorter{make([]entry, n), make([]float64, n), make([]int, n)}
+	for ; size < n; size *= 2 {
+		src, dst, size := s, buf, size
+		pool.each((n+2*size-1)/(2*size), func(i int) {
+			lo := 2 * i * size
+			mergeRuns(dst, src, lo, lo+size, lo+2*size)
+		})
+		s, buf = buf, s
+	}
+	if &s.entries[0] != &entries[0] {
+		copy(entries, s.entries)
+		copy(keys, s.keys)
+	}
+}
+
+// mergeRuns merges the sorted runs src[lo:mid] and src[mid:hi] into
+// dst[lo:hi]. The bounds are clipped to the length of src.
+func mergeRuns(dst, src keySorter, lo, mid, hi int) {
+	if n := src.Len(); hi > n {
+		hi = n
+		if mid > n {
+			mid = n
+		}
+	}
+
+	i, j := lo, mid
+	for k := lo; k < hi; k++ {
+		from := i
+		if i == mid || (j < hi && src.Less(j, i)) {
+			from = j
+			j++
+		} else {
+			i++
+		}
+		dst.entries[k] = src.entries[from]
+		dst.keys[k] = src.keys[from]
+		dst.order[k] = src.order[from]
+	}
+}
+
+// keySorter sorts entries by their keys, breaking ties by their original
+// positions, so that the sorted order is unique.
+type keySorter struct {
+	entries []entry
+	keys    []float64
+	order   []int
+}
+
+func (s keySorter) Len() int { return len(s.entries) }
+
+// slice returns the sorter of the entries from lo to hi, clipped to the
+// length of s.
+func (s keySorter) slice(lo, hi int) keySorter {
+	if hi > len(s.entries) {
+		hi = len(s.entries)
+	}
+	return keySorter{s.entries[lo:hi], s.keys[lo:hi], s.order[lo:hi]}
+}
+
+func (s keySorter) Swap(i, j int) {
+	s.entries[i], s.entries[j] = s.entries[j], s.entries[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.order[i], s.order[j] = s.order[j], s.order[i]
+}
+
+func (s keySorter) Less(i, j int) bool {
+	return s.keys[i] < s.keys[j] || (s.keys[i] == s.keys[j] && s.order[i] < s.order[j])
+}
+
+// workerPool limits the number of goroutines used for bulk loading. The nil
+// pool runs everything on the calling goroutine.
+type workerPool chan struct{}
+
+// newWorkerPool returns a pool of the given number of goroutines, including
+// the calling one, or nil if there are fewer than two.
+func newWorkerPool(workers int) workerPool {
+	if workers < 2 {
+		return nil
+	}
+	return make(workerPool, workers-1)
+}
+
+// each calls fn for the indices from 0 to n-1, each on a spare goroutine of
+// the pool if there is one and on the calling goroutine otherwise, and waits
+// for all calls to return.
+func (pool workerPool) each(n int, fn func(i int)) {
+	var wg sync.WaitGroup
+	for i := 0; i < n-1; i++ {
+		i := i
+		pool.run(&wg, func() {
+			fn(i)
+		})
+	}
+	if n > 0 {
+		fn(n - 1)
+	}
+	wg.Wait()
+}
+
+// run calls fn on a new goroutine tracked by wg if the pool has one to spare,
+// and on the calling goroutine otherwise.
+func (pool workerPool) run(wg *sync.WaitGroup, fn func()) {
+	select {
+	case pool <- struct{}{}:
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-pool }()
+			fn()
+		}()
+	default:
+		fn()
+	}
+}
@@ -0,0 +1,270 @@
+package rtreego
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSTRBulkLoadingValidity(t *testing.T) {
+	var things []Spatial
+	for i := float64(0); i < float64(100); i++ {
+		things = append(things, mustRect(Point{i, i}, []float64{1, 1}))
+	}
+
+	testCases := []struct {
+		count int
+		max   int
+	}{
+		{
+			count: 5,
+			max:   2,
+		},
+		{
+			count: 33,
+			max:   5,
+		},
+		{
+			count: 34,
+			max:   7,
+		},
+		{
+			count: 100,
+			max:   3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("count=%d-max=%d", tc.count, tc.max), func(t *testing.T) {
+			rt := NewTreeWithOptions(2, 1, tc.max, Options{BulkLoadMode: STRBulkLoad}, things[:tc.count]...)
+			verify(t, rt)
+			if rt.Size() != tc.count {
+				t.Errorf("expected size %d, got %d", tc.count, rt.Size())
+			}
+		})
+	}
+}
+
+func TestSTRBulkLoadingPacksLeaves(t *testing.T) {
+	var things []Spatial
+	for x := 0; x < 10; x++ {
+		for y := 0; y < 10; y++ {
+			rect := Point{float64(x), float64(y)}.ToRect(0.1)
+			things = append(things, &rect)
+		}
+	}
+
+	rt := NewTreeWithOptions(2, 5, 10, Options{BulkLoadMode: STRBulkLoad}, things...)
+	verify(t, rt)
+
+	if rt.Depth() != 2 || len(rt.root.entries) != 10 {
+		t.Fatalf("expected 10 leaves below the root, got depth %d with %d entries", rt.Depth(), len(rt.root.entries))
+	}
+	for _, e := range rt.root.entries {
+		if len(e.child.entries) != 10 {
+			t.Errorf("expected full leaves, got %d entries", len(e.child.entries))
+		}
+	}
+
+	for _, thing := range things {
+		results := rt.SearchIntersect(thing.Bounds())
+		if len(results) != 1 || results[0] != thing {
+			t.Errorf("SearchIntersect(%v) = %v, expected only %v", thing.Bounds(), results, thing)
+		}
+	}
+}
+
+func TestSTRTile(t *testing.T) {
+	var entries []entry
+	for i := 0; i < 16; i++ {
+		bb := Point{float64(i % 4), float64(i / 4)}.ToRect(0.1)
+		entries = append(entries, entry{bb: bb})
+	}
+
+	// 4 groups in 2 slabs, so every group is a 2x2 block of the grid
+	var groups [][]entry
+	strTile(entries, 0, 2, 4, nil, func(group []entry) {
+		groups = append(groups, group)
+	})
+
+	if len(groups) != 4 {
+		t.Fatalf("expected 4 groups, got %d", len(groups))
+	}
+	for i, group := range groups {
+		block := group[0].bb.center()
+		for _, e := range group {
+			c := e.bb.center()
+			if int(c[0]+0.5)/2 != int(block[0]+0.5)/2 || int(c[1]+0.5)/2 != int(block[1]+0.5)/2 {
+				t.Errorf("expected group %d to be a block, got %v", i, e.bb)
+			}
+		}
+	}
+}
+
+func TestInsertBulk(t *testing.T) {
+	things := randomRects(1000, 6)
+	for _, mode := range []BulkLoadMode{OMTBulkLoad, STRBulkLoad, HilbertBulkLoad} {
+		for _, tc := range []struct {
+			initial, batch int
+		}{
+			{0, 5},
+			{0, 100},
+			{100, 150},
+			{500, 40},
+			{900, 100},
+			{990, 10},
+			{997, 3},
+		} {
+			t.Run(fmt.Sprintf("mode=%d-initial=%d-batch=%d", mode, tc.initial, tc.batch), func(t *testing.T) {
+				rt := NewTreeWithOptions(2, 3, 7, Options{BulkLoadMode: mode}, things[:tc.initial]...)
+				batch := things[tc.initial : tc.initial+tc.batch]
+				rt.InsertBulk(batch)
+
+				verify(t, rt)
+				if rt.Size() != tc.initial+tc.batch {
+					t.Errorf("expected size %d, got %d", tc.initial+tc.batch, rt.Size())
+				}
+				for _, thing := range things[:tc.initial+tc.batch] {
+					if !contains(thing, rt.SearchIntersect(thing.Bounds())) {
+						t.Fatalf("SearchIntersect failed to find %v", thing)
+					}
+				}
+				for _, thing := range batch {
+					if !rt.Delete(thing) {
+						t.Fatalf("Delete failed to delete %v", thing)
+					}
+				}
+				verify(t, rt)
+			})
+		}
+	}
+}
+
+func TestInsertBulkGraftsSubtree(t *testing.T) {
+	things := randomRects(600, 7)
+	rt := NewTree(2, 2, 5, things[:500]...)
+	height := rt.Depth()
+
+	rt.InsertBulk(things[500:])
+	verify(t, rt)
+	if rt.Depth() < height {
+		t.Errorf("expected depth of at least %d, got %d", height, rt.Depth())
+	}
+
+	// the batch is packed into one subtree instead of being spread over the
+	// leaves of the tree
+	var batchRoot *node
+	for _, thing := range things[500:] {
+		leaf := rt.findLeaf(rt.root, thing, defaultComparator)
+		n := leaf
+		for n.parent != nil && n.level < 3 {
+			n = n.parent
+		}
+		if batchRoot == nil {
+			batchRoot = n
+		} else if n != batchRoot {
+			t.Fatalf("expected all objects of the batch below the same node at level 3")
+		}
+	}
+}
+
+func TestInsertBulkHilbertInsert(t *testing.T) {
+	things := randomRects(700, 8)
+	opts := Options{
+		InsertMode:    HilbertInsert,
+		HilbertBounds: mustRect(Point{0, 0}, []float64{100, 100}),
+	}
+	rt := NewTreeWithOptions(2, 2, 6, opts, things[:500]...)
+	rt.InsertBulk(things[500:])
+
+	verify(t, rt)
+	verifyHilbertOrder(t, rt, rt.root)
+	for _, thing := range things {
+		if !contains(thing, rt.SearchIntersect(thing.Bounds())) {
+			t.Fatalf("SearchIntersect failed to find %v", thing)
+		}
+	}
+}
+
+// ensureSameTree checks that the subtrees of a and b have the same structure,
+// bounding boxes and objects.
+func ensureSameTree(t *testing.T, a, b *node) {
+	t.Helper()
+	if a.level != b.level || a.leaf != b.leaf || len(a.entries) != len(b.entries) {
+		t.Fatalf("nodes differ: %v != %v", a, b)
+	}
+	for i := range a.entries {
+		x, y := a.entries[i], b.entries[i]
+		if !rectEq(x.bb, y.bb) || x.obj != y.obj {
+			t.Fatalf("entries differ: %v != %v", x, y)
+		}
+		if !a.leaf {
+			if x.child.parent != a || y.child.parent != b {
+				t.Fatalf("failed to update parent pointer")
+			}
+			ensureSameTree(t, x.child, y.child)
+		}
+	}
+}
+
+func TestSortByKeysParallel(t *testing.T) {
+	n := 5*minSortRun + 17
+	things := make([]Spatial, n)
+	keys := make([]float64, n)
+	for i := range things {
+		// many ties, which have to keep their order
+		rect := Point{float64(i % 1000), 0}.ToRect(0.5)
+		things[i] = &rect
+	}
+	expected := (&Rtree{}).objectEntries(things)
+	for i, e := range expected {
+		keys[i] = e.bb.p[0]
+	}
+	sortByKeys(expected, keys, nil)
+
+	for _, workers := range []int{2, 3, 8} {
+		entries := (&Rtree{}).objectEntries(things)
+		for i, e := range entries {
+			keys[i] = e.bb.p[0]
+		}
+		sortByKeys(entries, keys, newWorkerPool(workers))
+		for i := range entries {
+			if entries[i].obj != expected[i].obj {
+				t.Fatalf("%d workers: entry %d is %v, expected %v", workers, i, entries[i].obj, expected[i].obj)
+			}
+		}
+	}
+
+	index := make(map[Spatial]int, n)
+	for i, thing := range things {
+		index[thing] = i
+	}
+	for i := 1; i < n; i++ {
+		a, b := expected[i-1], expected[i]
+		if a.bb.p[0] > b.bb.p[0] || (a.bb.p[0] == b.bb.p[0] && index[a.obj] > index[b.obj]) {
+			t.Fatalf("entries not sorted at %d: %v before %v", i, a, b)
+		}
+	}
+}
+
+func TestParallelBulkLoad(t *testing.T) {
+	things := randomRects(3*minSortRun, 29)
+	for x := 0; x < 20; x++ {
+		// many ties in the sorted coordinates
+		rect := Point{float64(x), float64(x % 3)}.ToRect(0.5)
+		things = append(things, &rect, &rect)
+	}
+
+	for _, mode := range []BulkLoadMode{OMTBulkLoad, STRBulkLoad} {
+		sequential := NewTreeWithOptions(2, 3, 6, Options{BulkLoadMode: mode}, things...)
+		for _, workers := range []int{2, 3, 8} {
+			opts := Options{BulkLoadMode: mode, BulkLoadWorkers: workers}
+			parallel := NewTreeWithOptions(2, 3, 6, opts, things...)
+			verify(t, parallel)
+			if parallel.Size() != sequential.Size() || parallel.Depth() != sequential.Depth() {
+				t.Fatalf("mode %d with %d workers: size %d and depth %d, expected %d and %d", mode, workers,
+					parallel.Size(), parallel.Depth(), sequential.Size(), sequential.Depth())
+			}
+			ensureSameTree(t, parallel.root, sequential.root)
+		}
+	}
+}
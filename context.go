@@ -0,0 +1,74 @@
+// Copyright 2012 Daniel Connelly.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtreego
+
+import (
+	"context"
+)
+
+// cancelCheckInterval is the number of steps of a traversal between two
+// checks of its context.
+const cancelCheckInterval = 64
+
+// canceler checks a context for cancellation every cancelCheckInterval steps
+// of a traversal, starting with the first one.
+type canceler struct {
+	ctx   context.Context
+	steps int
+	err   error
+}
+
+// canceled reports whether the context is done, checking it if it is time.
+func (c *canceler) canceled() bool {
+	if c.err == nil && c.steps%cancelCheckInterval == 0 {
+		c.err = c.ctx.Err()
+	}
+	c.steps++
+	return c.err != nil
+}
+
+// SearchIntersectContext is like SearchIntersect, but stops the search once
+// ctx is done. It then returns the objects found so far along with the error
+// of ctx.
+func (tree *Rtree) SearchIntersectContext(ctx context.Context, bb Rect, filters ...Filter) ([]Spatial, error) {
+	if err := ctx.Err(); err != nil {
+		return []Spatial{}, err
+	}
+
+	// the nodes and the objects are checked alike, so that the search stops
+	// even if the root is a leaf
+	c := canceler{ctx: ctx}
+	pred := func(r, img Rect) bool {
+		return !c.canceled() && intersect(r, img)
+	}
+	results := tree.searchImages(bb, pred, pred, filters)
+	return results, c.err
+}
+
+// NearestNeighborsContext is like NearestNeighbors, but stops the search once
+// ctx is done. It then returns the nearest neighbors found so far, which are
+// the closest objects to p in increasing order of distance, along with the
+// error of ctx. Unlike in NearestNeighbors, the filters are called with the
+// neighbors accepted so far in their final order.
+func (tree *Rtree) NearestNeighborsContext(ctx context.Context, k int, p Point, filters ...Filter) ([]Spatial, error) {
+	c := canceler{ctx: ctx}
+	results := []Spatial{}
+	it := tree.NearestIterator(p)
+	for len(results) < k && !c.canceled() {
+		obj, _, ok := it.Next()
+		if !ok {
+			break
+		}
+
+		refuse, abort := applyFilters(results, obj, filters)
+		if !refuse {
+			results = append(results, obj)
+		}
+		if abort {
+			break
+		}
+	}
+	return results, c.err
+}
@@ -0,0 +1,108 @@
+package rtreego
+
+import (
+	"context"
+	"testing"
+)
+
+// cancelFilter cancels the search once the results reach the given size,
+// without refusing any object.
+func cancelFilter(cancel context.CancelFunc, size int) Filter {
+	return func(results []Spatial, object Spatial) (refuse, abort bool) {
+		if len(results) >= size {
+			cancel()
+		}
+		return false, false
+	}
+}
+
+func TestSearchIntersectContext(t *testing.T) {
+	things := randomRects(2000, 30)
+	rt := NewTree(2, 3, 6, things...)
+	bb := mustRect(Point{10, 10}, []float64{50, 50})
+	expected := rt.SearchIntersect(bb)
+
+	results, err := rt.SearchIntersectContext(context.Background(), bb)
+	if err != nil {
+		t.Errorf("SearchIntersectContext failed: %v", err)
+	}
+	if len(results) != len(expected) {
+		t.Errorf("SearchIntersectContext returned %d objects, expected %d", len(results), len(expected))
+	}
+	ensureDisorderedSubset(t, results, expected)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results, err = rt.SearchIntersectContext(ctx, bb, cancelFilter(cancel, 10))
+	if err != context.Canceled {
+		t.Errorf("expected SearchIntersectContext to fail with %v, got %v", context.Canceled, err)
+	}
+	if len(results) < 10 || len(results) >= len(expected) {
+		t.Errorf("expected SearchIntersectContext to stop after 10 of %d objects, got %d", len(expected), len(results))
+	}
+	ensureDisorderedSubset(t, results, expected)
+
+	results, err = rt.SearchIntersectContext(ctx, bb)
+	if err != context.Canceled || len(results) != 0 {
+		t.Errorf("expected SearchIntersectContext with a canceled context to return nothing, got %d objects and %v", len(results), err)
+	}
+}
+
+func TestContextSmallTree(t *testing.T) {
+	// the root of the tree is a leaf
+	things := randomRects(6, 32)
+	rt := NewTree(2, 3, 6, things...)
+	if rt.Depth() != 1 {
+		t.Fatalf("expected a tree of depth 1, got %d", rt.Depth())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := rt.SearchIntersectContext(ctx, rt.root.computeBoundingBox())
+	if err != context.Canceled || len(results) != 0 {
+		t.Errorf("expected SearchIntersectContext with a canceled context to return nothing, got %d objects and %v", len(results), err)
+	}
+	results, err = rt.NearestNeighborsContext(ctx, 6, Point{50, 50})
+	if err != context.Canceled || len(results) != 0 {
+		t.Errorf("expected NearestNeighborsContext with a canceled context to return nothing, got %d objects and %v", len(results), err)
+	}
+}
+
+func TestNearestNeighborsContext(t *testing.T) {
+	things := randomRects(2000, 31)
+	rt := NewTree(2, 3, 6, things...)
+	p := Point{50, 50}
+	expected := rt.NearestNeighbors(500, p)
+
+	results, err := rt.NearestNeighborsContext(context.Background(), 500, p)
+	if err != nil {
+		t.Errorf("NearestNeighborsContext failed: %v", err)
+	}
+	if len(results) != len(expected) {
+		t.Errorf("NearestNeighborsContext returned %d objects, expected %d", len(results), len(expected))
+	}
+	ensureOrderedSubset(t, results, expected)
+
+	// the partial results are the nearest neighbors found so far
+	ctx, cancel := context.WithCancel(context.Background())
+	results, err = rt.NearestNeighborsContext(ctx, 500, p, cancelFilter(cancel, 10))
+	if err != context.Canceled {
+		t.Errorf("expected NearestNeighborsContext to fail with %v, got %v", context.Canceled, err)
+	}
+	if len(results) < 10 || len(results) >= len(expected) {
+		t.Errorf("expected NearestNeighborsContext to stop after 10 of %d objects, got %d", len(expected), len(results))
+	}
+	ensureOrderedSubset(t, results, expected)
+
+	results, err = rt.NearestNeighborsContext(ctx, 500, p)
+	if err != context.Canceled || len(results) != 0 {
+		t.Errorf("expected NearestNeighborsContext with a canceled context to return nothing, got %d objects and %v", len(results), err)
+	}
+
+	// filters see the accepted neighbors
+	results, _ = rt.NearestNeighborsContext(context.Background(), 500, p, LimitFilter(5))
+	ensureOrderedSubset(t, results, expected)
+	if len(results) != 5 {
+		t.Errorf("expected LimitFilter to stop NearestNeighborsContext after 5 objects, got %d", len(results))
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2012 Daniel Connelly.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtreego
+
+import (
+	"fmt"
+)
+
+// NewGeoRect constructs the rectangle spanning the longitudes from west to
+// east and the latitudes from south to north, in degrees. Unlike
+// NewRectFromPoints, the longitudes are not swapped: if west is greater than
+// east, the rectangle crosses the antimeridian, and its eastern side is
+// extended past 180 degrees. For example, the rectangle from 170 to -170
+// degrees spans the longitudes from 170 to 190.
+//
+// Queries only take the wrapping into account in trees created with
+// Options.WrapLongitude.
+func NewGeoRect(west, south, east, north float64) (r Rect, err error) {
+	for _, lon := range []float64{west, east} {
+		if lon < -180 || lon > 180 {
+			err = fmt.Errorf("rtreego: longitude %v out of range [-180, 180]", lon)
+			return
+		}
+	}
+	for _, lat := range []float64{south, north} {
+		if lat < -90 || lat > 90 {
+			err = fmt.Errorf("rtreego: latitude %v out of range [-90, 90]", lat)
+			return
+		}
+	}
+	if south > north {
+		err = DistError(north - south)
+		return
+	}
+
+	if west > east {
+		east += 360
+	}
+	r = Rect{p: Point{west, south}, q: Point{east, north}}
+	return
+}
@@ -0,0 +1,127 @@
+package rtreego
+
+import (
+	"math"
+	"testing"
+)
+
+func mustGeoRect(west, south, east, north float64) Rect {
+	r, err := NewGeoRect(west, south, east, north)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func TestNewGeoRect(t *testing.T) {
+	r := mustGeoRect(-10, -5, 20, 15)
+	expected := Rect{Point{-10, -5}, Point{20, 15}}
+	if !rectEq(r, expected) {
+		t.Errorf("expected %v, got %v", expected, r)
+	}
+
+	r = mustGeoRect(170, -5, -170, 15)
+	expected = Rect{Point{170, -5}, Point{190, 15}}
+	if !rectEq(r, expected) {
+		t.Errorf("expected antimeridian-crossing %v, got %v", expected, r)
+	}
+
+	for _, bad := range [][4]float64{
+		{-190, 0, 10, 10},
+		{0, 0, 181, 10},
+		{0, -91, 10, 10},
+		{0, 10, 10, 0},
+	} {
+		if _, err := NewGeoRect(bad[0], bad[1], bad[2], bad[3]); err == nil {
+			t.Errorf("expected NewGeoRect%v to fail", bad)
+		}
+	}
+}
+
+func TestWrapLongitudeSearch(t *testing.T) {
+	rects := []Rect{
+		mustGeoRect(175, -20, 178, -15),   // Fiji, west of the antimeridian
+		mustGeoRect(-179, -20, -178, -15), // Fiji, east of the antimeridian
+		mustGeoRect(172, -50, -172, -30),  // crossing the antimeridian
+		mustGeoRect(-160, 15, -150, 25),   // Hawaii
+		mustGeoRect(0, 45, 10, 55),        // Europe
+		mustGeoRect(-10, 30, 20, 60),      // Europe
+		mustGeoRect(100, -10, 150, 10),    // Indonesia
+		mustGeoRect(-80, -50, -40, 10),    // South America
+	}
+	things := []Spatial{}
+	for i := range rects {
+		things = append(things, &rects[i])
+	}
+
+	dynamic := NewTreeWithOptions(2, 2, 3, Options{WrapLongitude: true})
+	for _, thing := range things {
+		dynamic.Insert(thing)
+	}
+	trees := map[string]*Rtree{
+		"dynamically built": dynamic,
+		"bulk-loaded":       NewTreeWithOptions(2, 2, 3, Options{WrapLongitude: true}, things...),
+		"STR bulk-loaded":   NewTreeWithOptions(2, 2, 3, Options{WrapLongitude: true, BulkLoadMode: STRBulkLoad}, things...),
+	}
+
+	for name, rt := range trees {
+		t.Run(name, func(t *testing.T) {
+			pacific := mustGeoRect(170, -25, -175, -10)
+			results := rt.SearchIntersect(pacific)
+			expected := []Spatial{things[0], things[1]}
+			if len(results) != len(expected) {
+				t.Errorf("SearchIntersect(%v) = %v, expected %v", pacific, results, expected)
+			}
+			ensureDisorderedSubset(t, results, expected)
+
+			// only the eastern side of the crossing rectangle
+			east := mustGeoRect(-175, -45, -170, -35)
+			results = rt.SearchIntersect(east)
+			if len(results) != 1 || results[0] != things[2] {
+				t.Errorf("SearchIntersect(%v) = %v, expected %v", east, results, things[2])
+			}
+
+			wide := mustGeoRect(170, -60, -140, 30)
+			results = rt.SearchContained(wide)
+			expected = []Spatial{things[0], things[1], things[2], things[3]}
+			if len(results) != len(expected) {
+				t.Errorf("SearchContained(%v) = %v, expected %v", wide, results, expected)
+			}
+			ensureDisorderedSubset(t, results, expected)
+
+			results = rt.SearchContaining(mustGeoRect(178, -45, -178, -40))
+			if len(results) != 1 || results[0] != things[2] {
+				t.Errorf("SearchContaining across the antimeridian = %v, expected %v", results, things[2])
+			}
+
+			results = rt.SearchPoint(Point{-175, -40})
+			if len(results) != 1 || results[0] != things[2] {
+				t.Errorf("SearchPoint east of the antimeridian = %v, expected %v", results, things[2])
+			}
+
+			// the whole globe contains every object
+			results = rt.SearchContained(mustGeoRect(-180, -90, 180, 90))
+			if len(results) != len(things) {
+				t.Errorf("expected the whole globe to contain %d objects, got %d", len(things), len(results))
+			}
+
+			// a query not crossing the antimeridian finds each object once
+			results = rt.SearchIntersect(mustGeoRect(-180, -90, 0, 90))
+			expected = []Spatial{things[1], things[2], things[3], things[5], things[7]}
+			if len(results) != len(expected) {
+				t.Errorf("SearchIntersect of the western hemisphere = %v, expected %v", results, expected)
+			}
+			ensureDisorderedSubset(t, results, expected)
+		})
+	}
+}
+
+func TestHaversineAntimeridianRect(t *testing.T) {
+	r := mustGeoRect(170, -10, -170, 10)
+	if d := (Haversine{}).MinDist(Point{-175, 0}, r); d != 0 {
+		t.Errorf("expected point inside %v to have distance 0, got %v", r, d)
+	}
+	if d, expected := (Haversine{}).MinDist(Point{-160, 0}, r), (Haversine{}).Dist(Point{-160, 0}, Point{-170, 0}); math.Abs(d-expected) > 1e-6 {
+		t.Errorf("expected MinDist to %v to be %v, got %v", r, expected, d)
+	}
+}
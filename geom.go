@@ -75,6 +75,26 @@ func (p Point) minDist(r Rect) float64 {
 	return sum
 }
 
+// rectMinDist computes the square of the distance between the closest points
+// of two rectangles, which is zero if they intersect.
+func rectMinDist(r1, r2 Rect) float64 {
+	if len(r1.p) != len(r2.p) {
+		panic(DimError{len(r1.p), len(r2.p)})
+	}
+
+	sum := 0.0
+	for i := range r1.p {
+		if r1.q[i] < r2.p[i] {
+			d := r2.p[i] - r1.q[i]
+			sum += d * d
+		} else if r2.q[i] < r1.p[i] {
+			d := r1.p[i] - r2.q[i]
+			sum += d * d
+		}
+	}
+	return sum
+}
+
 // minMaxDist computes the minimum of the maximum distances from p to points
 // on r.  If r is the bounding box of some geometric objects, then there is
 // at least one object contained in r within minMaxDist(p, r) of p.
@@ -240,6 +260,50 @@ func (r Rect) margin() float64 {
 	return math.Pow(2, float64(dim-1)) * sum
 }
 
+// center returns the center point of r.
+func (r Rect) center() Point {
+	c := make(Point, len(r.p))
+	for i, a := range r.p {
+		c[i] = (a + r.q[i]) / 2
+	}
+	return c
+}
+
+// copy returns a deep copy of r.
+func (r Rect) copy() Rect {
+	return Rect{p: r.p.Copy(), q: r.q.Copy()}
+}
+
+// intersection returns the rectangle in which r1 and r2 overlap, which must
+// intersect.
+func intersection(r1, r2 Rect) Rect {
+	r := Rect{p: make(Point, len(r1.p)), q: make(Point, len(r1.q))}
+	for i := range r1.p {
+		r.p[i] = math.Max(r1.p[i], r2.p[i])
+		r.q[i] = math.Min(r1.q[i], r2.q[i])
+	}
+	return r
+}
+
+// overlapSize computes the measure of the intersection of two rectangles, or
+// zero if they do not intersect.
+func overlapSize(r1, r2 Rect) float64 {
+	dim := len(r1.p)
+	if len(r2.p) != dim {
+		panic(DimError{dim, len(r2.p)})
+	}
+
+	size := 1.0
+	for i := range r1.p {
+		lo, hi := math.Max(r1.p[i], r2.p[i]), math.Min(r1.q[i], r2.q[i])
+		if hi <= lo {
+			return 0
+		}
+		size *= hi - lo
+	}
+	return size
+}
+
 // containsPoint tests whether p is located inside or on the boundary of r.
 func (r Rect) containsPoint(p Point) bool {
 	if len(p) != len(r.p) {
@@ -322,6 +386,22 @@ func intersect(r1, r2 Rect) bool {
 	return true
 }
 
+// overlaps tests whether r1 and r2 have at least one point in common. Unlike
+// intersect, rectangles that only touch each other are considered to overlap.
+func overlaps(r1, r2 Rect) bool {
+	dim := len(r1.p)
+	if len(r2.p) != dim {
+		panic(DimError{dim, len(r2.p)})
+	}
+
+	for i := range r1.p {
+		if r2.q[i] < r1.p[i] || r1.q[i] < r2.p[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // ToRect constructs a rectangle containing p with side lengths 2*tol.
 func (p Point) ToRect(tol float64) Rect {
 	dim := len(p)
@@ -154,6 +154,27 @@ func TestRectMargin(t *testing.T) {
 	}
 }
 
+func TestRectCenter(t *testing.T) {
+	rect := mustRect(Point{1.0, -2.5, 3.0}, []float64{2.5, 8.0, 1.5})
+	expected := Point{2.25, 1.5, 3.75}
+	if c := rect.center(); c.dist(expected) >= EPS {
+		t.Errorf("Expected %v.center() == %v, got %v", rect, expected, c)
+	}
+}
+
+func TestOverlapSize(t *testing.T) {
+	r1 := mustRect(Point{0, 0}, []float64{2, 2})
+	r2 := mustRect(Point{1, 1}, []float64{2, 3})
+	if size := overlapSize(r1, r2); size != 1 {
+		t.Errorf("Expected overlapSize(%v, %v) == 1, got %v", r1, r2, size)
+	}
+
+	r3 := mustRect(Point{2, 0}, []float64{1, 1})
+	if size := overlapSize(r1, r3); size != 0 {
+		t.Errorf("Expected overlapSize(%v, %v) == 0, got %v", r1, r3, size)
+	}
+}
+
 func TestContainsPoint(t *testing.T) {
 	p := Point{3.7, -2.4, 0.0}
 	lengths := []float64{6.2, 1.1, 4.9}
@@ -284,6 +305,18 @@ func TestOverlapIntersection(t *testing.T) {
 	}
 }
 
+func TestOverlaps(t *testing.T) {
+	r1 := mustRect(Point{0, 0}, []float64{1, 1})
+	r2 := mustRect(Point{1, 0.5}, []float64{1, 1})
+	r3 := mustRect(Point{1.5, 0}, []float64{1, 1})
+	if !overlaps(r1, r2) || !overlaps(r2, r1) {
+		t.Errorf("Expected %v and %v to overlap", r1, r2)
+	}
+	if overlaps(r1, r3) || overlaps(r3, r1) {
+		t.Errorf("Expected %v and %v not to overlap", r1, r3)
+	}
+}
+
 func TestToRect(t *testing.T) {
 	x := Point{3.7, -2.4, 0.0}
 	tol := 0.05
@@ -352,6 +385,23 @@ func TestMinDistPositive(t *testing.T) {
 	}
 }
 
+func TestRectMinDist(t *testing.T) {
+	r1 := Rect{Point{0, 0, 0}, Point{1, 2, 3}}
+	r2 := Rect{Point{3, 1, -4}, Point{4, 5, -1}}
+	expected := float64((3-1)*(3-1) + (0-(-1))*(0-(-1)))
+	if d := rectMinDist(r1, r2); math.Abs(d-expected) > EPS {
+		t.Errorf("Expected rectMinDist(%v, %v) == %v, got %v", r1, r2, expected, d)
+	}
+	if d := rectMinDist(r2, r1); math.Abs(d-expected) > EPS {
+		t.Errorf("Expected rectMinDist(%v, %v) == %v, got %v", r2, r1, expected, d)
+	}
+
+	r3 := Rect{Point{0.5, 1, 2}, Point{5, 5, 5}}
+	if d := rectMinDist(r1, r3); d != 0 {
+		t.Errorf("Expected rectMinDist(%v, %v) == 0, got %v", r1, r3, d)
+	}
+}
+
 func TestMinMaxdist(t *testing.T) {
 	p := Point{-3, -2, -1}
 	r := Rect{Point{0, 0, 0}, Point{1, 2, 3}}
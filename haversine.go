@@ -0,0 +1,113 @@
+// Copyright 2012 Daniel Connelly.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtreego
+
+import (
+	"math"
+)
+
+// EarthRadius is the mean radius of the Earth in meters.
+const EarthRadius = 6371008.8
+
+// Haversine is the great-circle metric for trees of geographic coordinates.
+// Points are (longitude, latitude) pairs in degrees, and distances are
+// measured in meters along the surface of a sphere with radius EarthRadius.
+// Bounding boxes are ranges of longitudes and latitudes, which may extend past
+// 180 degrees to cross the antimeridian, as constructed by NewGeoRect.
+type Haversine struct{}
+
+// Dist implements Metric.
+func (Haversine) Dist(p, q Point) float64 {
+	if len(p) != 2 {
+		panic(DimError{2, len(p)})
+	}
+	if len(q) != 2 {
+		panic(DimError{2, len(q)})
+	}
+	h := haversineDist(hav(radians(p[0]-q[0])), radians(p[1]), radians(q[1]))
+	return haversineMeters(h)
+}
+
+// MinDist implements Metric. The closest point of r lies on the meridian or
+// the parallel bounding r which is closest to p, or in one of its corners.
+//
+// Implemented per "Geodetic Distance Queries on R-Trees for Indexing
+// Geographic Data" by E. Schubert, A. Zimek and H.-P. Kriegel, SSTD,
+// p. 146-164, 2013.
+func (Haversine) MinDist(p Point, r Rect) float64 {
+	if len(p) != 2 {
+		panic(DimError{2, len(p)})
+	}
+	if len(r.p) != 2 {
+		panic(DimError{2, len(r.p)})
+	}
+	lon, lat := p[0], p[1]
+	minLat, maxLat := r.p[1], r.q[1]
+
+	// rectangles crossing the antimeridian extend past 180 degrees, so look
+	// for p in the first turn east of the western side
+	east := math.Mod(lon-r.p[0], 360)
+	if east < 0 {
+		east += 360
+	}
+	if r.p[0]+east <= r.q[0] {
+		// p is north or south of r, or inside of it
+		if lat < minLat {
+			return haversineMeters(hav(radians(minLat - lat)))
+		} else if lat > maxLat {
+			return haversineMeters(hav(radians(lat - maxLat)))
+		}
+		return 0
+	}
+
+	// p is west or east of r, so the closest point is on the meridian with
+	// the smaller difference in longitude
+	havLon := math.Min(hav(radians(lon-r.p[0])), hav(radians(lon-r.q[0])))
+
+	// latitude of the point of that meridian closest to p
+	var closest float64
+	if cosLon := 1 - 2*havLon; cosLon <= 0 {
+		closest = math.Copysign(90, lat)
+	} else {
+		closest = degrees(math.Atan(math.Tan(radians(lat)) / cosLon))
+	}
+
+	phi := radians(lat)
+	if closest > minLat && closest < maxLat {
+		return haversineMeters(haversineDist(havLon, phi, radians(closest)))
+	}
+	h := math.Min(
+		haversineDist(havLon, phi, radians(minLat)),
+		haversineDist(havLon, phi, radians(maxLat)),
+	)
+	return haversineMeters(h)
+}
+
+// hav returns the haversine of an angle in radians.
+func hav(theta float64) float64 {
+	s := math.Sin(theta / 2)
+	return s * s
+}
+
+// haversineDist returns the haversine of the central angle between two points
+// with latitudes phi1 and phi2 in radians, given the haversine of the
+// difference of their longitudes.
+func haversineDist(havLon, phi1, phi2 float64) float64 {
+	return hav(phi1-phi2) + math.Cos(phi1)*math.Cos(phi2)*havLon
+}
+
+// haversineMeters converts the haversine of a central angle into the length
+// of the arc in meters.
+func haversineMeters(h float64) float64 {
+	return 2 * EarthRadius * math.Asin(math.Sqrt(math.Min(1, math.Max(0, h))))
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
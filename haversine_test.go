@@ -0,0 +1,129 @@
+package rtreego
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestHaversineDist(t *testing.T) {
+	degree := EarthRadius * math.Pi / 180
+	tests := []struct {
+		p, q     Point
+		expected float64
+	}{
+		{Point{0, 0}, Point{1, 0}, degree},
+		{Point{0, 0}, Point{0, -1}, degree},
+		{Point{179.5, 0}, Point{-179.5, 0}, degree},
+		{Point{10, 90}, Point{-150, -90}, 180 * degree},
+		{Point{0, 60}, Point{180, 60}, 60 * degree},
+		// London to Paris
+		{Point{-0.1278, 51.5074}, Point{2.3522, 48.8566}, 343556},
+	}
+	for _, test := range tests {
+		if d := (Haversine{}).Dist(test.p, test.q); math.Abs(d-test.expected) > 1 {
+			t.Errorf("expected Dist(%v, %v) == %v, got %v", test.p, test.q, test.expected, d)
+		}
+	}
+}
+
+func TestHaversineMinDist(t *testing.T) {
+	r := mustRect(Point{10, 40}, []float64{20, 10})
+	tests := []struct {
+		p        Point
+		expected float64
+	}{
+		{Point{15, 45}, 0},
+		{Point{10, 40}, 0},
+		{Point{20, 30}, (Haversine{}).Dist(Point{20, 30}, Point{20, 40})},
+		{Point{20, 55}, (Haversine{}).Dist(Point{20, 55}, Point{20, 50})},
+		{Point{0, 30}, (Haversine{}).Dist(Point{0, 30}, Point{10, 40})},
+		// the shortest way passes close to the pole to the nearest corner
+		{Point{-170, 45}, (Haversine{}).Dist(Point{-170, 45}, Point{30, 50})},
+	}
+	for _, test := range tests {
+		if d := (Haversine{}).MinDist(test.p, r); math.Abs(d-test.expected) > 1 {
+			t.Errorf("expected MinDist(%v, %v) == %v, got %v", test.p, r, test.expected, d)
+		}
+	}
+
+	// next to a meridian, the closest point lies on the edge, poleward of p
+	p := Point{40, 45}
+	closest := (Haversine{}).MinDist(p, r)
+	if edge := (Haversine{}).Dist(p, Point{30, 45}); closest >= edge {
+		t.Errorf("expected MinDist(%v, %v) = %v to be smaller than %v", p, r, closest, edge)
+	}
+}
+
+func TestHaversineMinDistLowerBound(t *testing.T) {
+	rnd := rand.New(rand.NewSource(14))
+	for i := 0; i < 200; i++ {
+		lon, lat := rnd.Float64()*340-170, rnd.Float64()*160-80
+		r := mustRect(Point{lon, lat}, []float64{rnd.Float64() * 20, rnd.Float64() * 10})
+		p := Point{rnd.Float64()*360 - 180, rnd.Float64()*180 - 90}
+
+		// sample the boundary of r
+		sampled := math.Inf(1)
+		const steps = 200
+		for j := 0; j <= steps; j++ {
+			f := float64(j) / steps
+			x := r.p[0] + f*(r.q[0]-r.p[0])
+			y := r.p[1] + f*(r.q[1]-r.p[1])
+			for _, q := range []Point{{x, r.p[1]}, {x, r.q[1]}, {r.p[0], y}, {r.q[0], y}} {
+				sampled = math.Min(sampled, (Haversine{}).Dist(p, q))
+			}
+		}
+
+		d := (Haversine{}).MinDist(p, r)
+		if d > sampled+1e-6 {
+			t.Errorf("MinDist(%v, %v) = %v is larger than the sampled distance %v", p, r, d, sampled)
+		}
+		if d < sampled-1000 {
+			t.Errorf("MinDist(%v, %v) = %v is much smaller than the sampled distance %v", p, r, d, sampled)
+		}
+	}
+}
+
+func TestHaversineNearestNeighbors(t *testing.T) {
+	rnd := rand.New(rand.NewSource(15))
+	things := make([]Spatial, 300)
+	for i := range things {
+		p := Point{rnd.Float64()*360 - 180, rnd.Float64()*180 - 90}
+		r := p.ToRect(0)
+		things[i] = &r
+	}
+
+	rt := NewTreeWithOptions(2, 3, 6, Options{Metric: Haversine{}}, things...)
+	for _, p := range []Point{{179.9, 10}, {-30, 85}, {0, 0}, {100, -70}} {
+		expected := append([]Spatial{}, things...)
+		sort.SliceStable(expected, func(i, j int) bool {
+			return (Haversine{}).Dist(p, expected[i].Bounds().p) < (Haversine{}).Dist(p, expected[j].Bounds().p)
+		})
+
+		objs, dists := rt.NearestNeighborsWithDistances(10, p)
+		for i := range objs {
+			if objs[i] != expected[i] {
+				t.Errorf("NearestNeighbors(%v) failed at index %d: %v != %v", p, i, objs[i], expected[i])
+			}
+			if d := (Haversine{}).Dist(p, expected[i].Bounds().p); math.Abs(dists[i]-d) > EPS {
+				t.Errorf("NearestNeighborsWithDistances(%v) distance at index %d: %v != %v", p, i, dists[i], d)
+			}
+		}
+	}
+}
+
+func TestHaversineAcrossAntimeridian(t *testing.T) {
+	east, west := Point{179.5, 0}.ToRect(0), Point{-179.5, 0}.ToRect(0)
+	other := Point{178, 0}.ToRect(0)
+	things := []Spatial{&east, &west, &other}
+	for i := 0; i < 10; i++ {
+		r := Point{float64(i*10 - 50), 0}.ToRect(0)
+		things = append(things, &r)
+	}
+
+	rt := NewTreeWithOptions(2, 2, 3, Options{Metric: Haversine{}}, things...)
+	p := Point{179.9, 0}
+	objs := rt.NearestNeighbors(3, p)
+	ensureOrderedSubset(t, objs, []Spatial{&east, &west, &other})
+}
@@ -0,0 +1,243 @@
+// Copyright 2012 Daniel Connelly.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtreego
+
+import (
+	"math"
+	"sort"
+)
+
+// hilbertCurve maps the points of a rectangular region onto a Hilbert curve
+// over a grid of 2^bits cells per dimension.
+type hilbertCurve struct {
+	bounds Rect
+	bits   uint
+}
+
+func newHilbertCurve(dim int, bounds Rect) *hilbertCurve {
+	if len(bounds.p) != dim {
+		panic(DimError{dim, len(bounds.p)})
+	}
+
+	// fit the index of a cell into 64 bits
+	bits := 64 / dim
+	if bits > 32 {
+		bits = 32
+	}
+	if bits < 1 {
+		bits = 1
+	}
+	return &hilbertCurve{bounds: bounds, bits: uint(bits)}
+}
+
+// value returns the position of p along the curve. Points outside of the
+// bounds of the curve are mapped to the closest cell.
+//
+// Implemented per "Programming the Hilbert curve" by J. Skilling, AIP
+// Conference Proceedings 707, p. 381-387, 2004.
+func (c *hilbertCurve) value(p Point) uint64 {
+	n := len(p)
+	max := float64(uint32(1)<<c.bits - 1)
+	x := make([]uint32, n)
+	for i, a := range p {
+		lo, hi := c.bounds.p[i], c.bounds.q[i]
+		if hi > lo {
+			x[i] = uint32(math.Max(0, math.Min(max, math.Floor((a-lo)/(hi-lo)*max))))
+		}
+	}
+
+	// inverse undo excess work
+	m := uint32(1) << (c.bits - 1)
+	for q := m; q > 1; q >>= 1 {
+		mask := q - 1
+		for i := 0; i < n; i++ {
+			if x[i]&q != 0 {
+				x[0] ^= mask
+			} else {
+				t := (x[0] ^ x[i]) & mask
+				x[0] ^= t
+				x[i] ^= t
+			}
+		}
+	}
+
+	// Gray encode
+	for i := 1; i < n; i++ {
+		x[i] ^= x[i-1]
+	}
+	t := uint32(0)
+	for q := m; q > 1; q >>= 1 {
+		if x[n-1]&q != 0 {
+			t ^= q - 1
+		}
+	}
+	for i := range x {
+		x[i] ^= t
+	}
+
+	// interleave the transposed bits into the index
+	var h uint64
+	for b := int(c.bits) - 1; b >= 0; b-- {
+		for i := range x {
+			h = h<<1 | uint64(x[i]>>uint(b)&1)
+		}
+	}
+	return h
+}
+
+// entryValue returns the Hilbert value of a leaf entry, which is the value of
+// the center of its bounding box, or the largest Hilbert value of the
+// subtree of a non-leaf entry.
+func (c *hilbertCurve) entryValue(e entry) uint64 {
+	// in a Hilbert R-tree, the largest value of a subtree is found in its
+	// last leaf entry
+	for e.child != nil {
+		e = e.child.entries[len(e.child.entries)-1]
+	}
+	return c.value(e.bb.center())
+}
+
+type hilbertSorter struct {
+	entries []entry
+	values  []uint64
+}
+
+func (s hilbertSorter) Len() int { return len(s.entries) }
+
+func (s hilbertSorter) Swap(i, j int) {
+	s.entries[i], s.entries[j] = s.entries[j], s.entries[i]
+	s.values[i], s.values[j] = s.values[j], s.values[i]
+}
+
+func (s hilbertSorter) Less(i, j int) bool {
+	return s.values[i] < s.values[j]
+}
+
+// sort orders leaf entries by the Hilbert value of their centers.
+func (c *hilbertCurve) sort(entries []entry) {
+	values := make([]uint64, len(entries))
+	for i, e := range entries {
+		values[i] = c.value(e.bb.center())
+	}
+	sort.Stable(hilbertSorter{entries, values})
+}
+
+// insertHilbert adds e to the tree at the specified level, keeping the
+// entries of every node ordered by their Hilbert values.
+//
+// Implemented per "Hilbert R-tree: An Improved R-tree Using Fractals" by
+// I. Kamel and C. Faloutsos, VLDB, p. 500-509, 1994.
+func (tree *Rtree) insertHilbert(e entry, level int) {
+	h := tree.hilbert.entryValue(e)
+
+	// descend into the subtree with the smallest largest Hilbert value
+	// greater than h, or the last subtree if there is none
+	n := tree.root
+	for !n.leaf && n.level != level {
+		i := tree.hilbertIndex(n, h)
+		if i == len(n.entries) {
+			i--
+		}
+		n = n.entries[i].child
+	}
+	n = tree.own(n)
+
+	i := tree.hilbertIndex(n, h)
+	n.entries = append(n.entries, entry{})
+	copy(n.entries[i+1:], n.entries[i:])
+	n.entries[i] = e
+
+	// update parent pointer if necessary
+	if e.child != nil {
+		e.child.parent = n
+	}
+
+	tree.handleOverflowHilbert(n)
+}
+
+// hilbertIndex returns the index of the first entry of n whose Hilbert
+// value is greater than h.
+func (tree *Rtree) hilbertIndex(n *node, h uint64) int {
+	return sort.Search(len(n.entries), func(i int) bool {
+		return tree.hilbert.entryValue(n.entries[i]) > h
+	})
+}
+
+// handleOverflowHilbert resolves overflows from n upwards and adjusts the
+// bounding boxes on the way. An overflowing node first shares its entries
+// with a sibling; only if both are full, they are split into three nodes.
+func (tree *Rtree) handleOverflowHilbert(n *node) {
+	for n != tree.root {
+		parent := n.parent
+		if len(n.entries) <= tree.MaxChildren {
+			en := n.getEntry()
+			prevBox := en.bb
+			en.bb = n.computeBoundingBox()
+			if en.bb.Equal(prevBox) {
+				// Nothing changed above this point.
+				return
+			}
+			n = parent
+			continue
+		}
+
+		// the cooperating sibling is the next node, or the previous one
+		// for the last node
+		i := 0
+		for parent.entries[i].child != n {
+			i++
+		}
+		if i == len(parent.entries)-1 && i > 0 {
+			i--
+		}
+		group := []*node{tree.own(parent.entries[i].child)}
+		if i+1 < len(parent.entries) {
+			group = append(group, tree.own(parent.entries[i+1].child))
+		}
+
+		var entries []entry
+		for _, sibling := range group {
+			entries = append(entries, sibling.entries...)
+		}
+		if len(entries) > len(group)*tree.MaxChildren {
+			// all cooperating nodes are full, add a new one after them
+			split := &node{parent: parent, leaf: n.leaf, level: n.level, gen: tree.gen}
+			group = append(group, split)
+			last := i + len(group) - 1
+			parent.entries = append(parent.entries, entry{})
+			copy(parent.entries[last+1:], parent.entries[last:])
+			parent.entries[last] = entry{child: split}
+		}
+
+		distributeEvenly(entries, group)
+		for j, sibling := range group {
+			parent.entries[i+j].bb = sibling.computeBoundingBox()
+		}
+		n = parent
+	}
+
+	if len(n.entries) > tree.MaxChildren {
+		// the root has no siblings, so split it in two halves
+		entries := n.entries
+		split := &node{leaf: n.leaf, level: n.level, gen: tree.gen}
+		distributeEvenly(entries, []*node{n, split})
+		tree.growRoot(n, split)
+	}
+}
+
+// distributeEvenly assigns the ordered entries to the nodes, keeping their
+// order and giving every node roughly the same number of entries.
+func distributeEvenly(entries []entry, nodes []*node) {
+	k := len(nodes)
+	start := 0
+	for j, n := range nodes {
+		end := start + (len(entries)-start)/(k-j)
+		n.entries = make([]entry, 0, end-start)
+		for _, e := range entries[start:end] {
+			assign(e, n)
+		}
+		start = end
+	}
+}
@@ -0,0 +1,203 @@
+package rtreego
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestHilbertCurveValue(t *testing.T) {
+	c := &hilbertCurve{bounds: mustRect(Point{0, 0}, []float64{1, 1}), bits: 1}
+	expected := []struct {
+		p Point
+		h uint64
+	}{
+		{Point{0, 0}, 0},
+		{Point{0, 1}, 1},
+		{Point{1, 1}, 2},
+		{Point{1, 0}, 3},
+	}
+	for _, e := range expected {
+		if h := c.value(e.p); h != e.h {
+			t.Errorf("value(%v) = %d, expected %d", e.p, h, e.h)
+		}
+	}
+}
+
+func TestHilbertCurveContinuity(t *testing.T) {
+	for _, dim := range []int{2, 3} {
+		t.Run(fmt.Sprintf("dim=%d", dim), func(t *testing.T) {
+			lengths := make([]float64, dim)
+			for i := range lengths {
+				lengths[i] = 3
+			}
+			c := &hilbertCurve{bounds: mustRect(make(Point, dim), lengths), bits: 2}
+
+			// every cell of the 4^dim grid is visited exactly once, and
+			// consecutive cells are adjacent
+			cells := 1 << uint(2*dim)
+			order := make([]Point, cells)
+			seen := make([]bool, cells)
+			for i := 0; i < cells; i++ {
+				p := make(Point, dim)
+				for d, k := 0, i; d < dim; d, k = d+1, k/4 {
+					p[d] = float64(k % 4)
+				}
+				h := c.value(p)
+				if h >= uint64(cells) || seen[h] {
+					t.Fatalf("value(%v) = %d is not a unique cell index", p, h)
+				}
+				seen[h] = true
+				order[h] = p
+			}
+			for i := 1; i < cells; i++ {
+				if d := order[i].dist(order[i-1]); d != 1 {
+					t.Errorf("cells %v and %v are not adjacent", order[i-1], order[i])
+				}
+			}
+		})
+	}
+}
+
+// verifyHilbertOrder checks that the entries of every node are ordered by
+// their Hilbert values.
+func verifyHilbertOrder(t *testing.T, rt *Rtree, n *node) {
+	for i := 1; i < len(n.entries); i++ {
+		if rt.hilbert.entryValue(n.entries[i-1]) > rt.hilbert.entryValue(n.entries[i]) {
+			t.Fatalf("entries at level %d are not in Hilbert order", n.level)
+		}
+	}
+	if !n.leaf {
+		for _, e := range n.entries {
+			verifyHilbertOrder(t, rt, e.child)
+		}
+	}
+}
+
+func TestHilbertBulkLoading(t *testing.T) {
+	var things []Spatial
+	for x := 0; x < 20; x++ {
+		for y := 0; y < 20; y++ {
+			rect := Point{float64(x), float64(y)}.ToRect(0.1)
+			things = append(things, &rect)
+		}
+	}
+
+	rt := NewTreeWithOptions(2, 4, 8, Options{BulkLoadMode: HilbertBulkLoad}, things...)
+	verify(t, rt)
+	if rt.Size() != len(things) {
+		t.Errorf("expected size %d, got %d", len(things), rt.Size())
+	}
+
+	leaves := 0
+	var countLeaves func(n *node)
+	countLeaves = func(n *node) {
+		if n.leaf {
+			leaves++
+			return
+		}
+		for _, e := range n.entries {
+			countLeaves(e.child)
+		}
+	}
+	countLeaves(rt.root)
+	if leaves != len(things)/rt.MaxChildren {
+		t.Errorf("expected %d full leaves, got %d", len(things)/rt.MaxChildren, leaves)
+	}
+
+	for _, thing := range things {
+		results := rt.SearchIntersect(thing.Bounds())
+		if len(results) != 1 || results[0] != thing {
+			t.Errorf("SearchIntersect(%v) = %v, expected only %v", thing.Bounds(), results, thing)
+		}
+	}
+}
+
+func TestHilbertInsert(t *testing.T) {
+	things := randomRects(500, 5)
+	opts := Options{
+		InsertMode:    HilbertInsert,
+		HilbertBounds: mustRect(Point{0, 0}, []float64{100, 100}),
+	}
+
+	for _, tc := range []struct {
+		name string
+		objs []Spatial
+	}{
+		{"dynamically built", nil},
+		{"bulk-loaded", things[:100]},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rt := NewTreeWithOptions(2, 2, 6, opts, tc.objs...)
+			for _, thing := range things[len(tc.objs):] {
+				rt.Insert(thing)
+			}
+			verify(t, rt)
+			verifyHilbertOrder(t, rt, rt.root)
+			if rt.Size() != len(things) {
+				t.Errorf("Insert failed to insert, got size %d", rt.Size())
+			}
+			for _, thing := range things {
+				if !contains(thing, rt.SearchIntersect(thing.Bounds())) {
+					t.Fatalf("SearchIntersect failed to find %v", thing)
+				}
+			}
+
+			for _, thing := range things[:250] {
+				if !rt.Delete(thing) {
+					t.Fatalf("Delete failed to delete %v", thing)
+				}
+			}
+			verify(t, rt)
+			verifyHilbertOrder(t, rt, rt.root)
+			for _, thing := range things[250:] {
+				if !contains(thing, rt.SearchIntersect(thing.Bounds())) {
+					t.Fatalf("SearchIntersect failed to find %v", thing)
+				}
+			}
+		})
+	}
+}
+
+func TestHilbertInsertDeferredSplit(t *testing.T) {
+	opts := Options{
+		InsertMode:    HilbertInsert,
+		HilbertBounds: mustRect(Point{0, 0}, []float64{10, 10}),
+	}
+	rt := NewTreeWithOptions(2, 1, 3, opts)
+	for i := 0; i < 10; i++ {
+		rect := Point{float64(i), 0}.ToRect(0.1)
+		rt.Insert(&rect)
+	}
+	verify(t, rt)
+
+	// with 2-to-3 splits, nodes other than the root are at least 2/3 full
+	for _, e := range rt.root.entries {
+		if len(e.child.entries) < 2 {
+			t.Errorf("expected leaves with at least 2 entries, got %d", len(e.child.entries))
+		}
+	}
+}
+
+func TestHilbertInsertRequiresBounds(t *testing.T) {
+	defer func() {
+		err, ok := recover().(error)
+		if !ok || !strings.Contains(err.Error(), "HilbertBounds") {
+			t.Errorf("expected an error naming HilbertBounds when it is missing, got %v", err)
+		}
+	}()
+	NewTreeWithOptions(2, 1, 3, Options{InsertMode: HilbertInsert})
+}
+
+func TestHilbertBoundsDimError(t *testing.T) {
+	defer func() {
+		if _, ok := recover().(DimError); !ok {
+			t.Errorf("expected a DimError for HilbertBounds of the wrong dimension")
+		}
+	}()
+	opts := Options{
+		InsertMode:    HilbertInsert,
+		HilbertBounds: mustRect(Point{0, 0, 0}, []float64{1, 1, 1}),
+	}
+	NewTreeWithOptions(2, 1, 3, opts)
+}
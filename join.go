@@ -0,0 +1,282 @@
+// Copyright 2012 Daniel Connelly.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtreego
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// Join calls fn for every pair of intersecting objects x from a and y from b,
+// until fn returns false. Both trees are traversed synchronously, so only
+// pairs of nodes with intersecting bounding boxes are visited. The trees must
+// have the same dimension.
+//
+// Implemented per "Efficient Processing of Spatial Joins Using R-trees" by
+// T. Brinkhoff, H.-P. Kriegel and B. Seeger, SIGMOD, p. 237-246, 1993.
+func Join(a, b *Rtree, fn func(x, y Spatial) bool) {
+	if a.Dim != b.Dim {
+		panic(DimError{a.Dim, b.Dim})
+	}
+	if a.size == 0 || b.size == 0 {
+		return
+	}
+
+	abb, bbb := a.root.computeBoundingBox(), b.root.computeBoundingBox()
+	if intersect(abb, bbb) {
+		join(a.root, abb, b.root, bbb, fn)
+	}
+}
+
+// join reports the intersecting pairs of objects in the subtrees of n and m,
+// whose bounding boxes nbb and mbb intersect. It returns false if fn stopped
+// the join.
+func join(n *node, nbb Rect, m *node, mbb Rect, fn func(x, y Spatial) bool) bool {
+	// descend the higher subtree until both nodes are at the same level
+	if n.level > m.level {
+		for _, e := range n.entries {
+			if intersect(e.bb, mbb) && !join(e.child, e.bb, m, mbb, fn) {
+				return false
+			}
+		}
+		return true
+	}
+	if m.level > n.level {
+		for _, e := range m.entries {
+			if intersect(nbb, e.bb) && !join(n, nbb, e.child, e.bb, fn) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// only entries intersecting both nodes can be part of a pair
+	space := intersection(nbb, mbb)
+	left := restrictEntries(n.entries, space)
+	right := restrictEntries(m.entries, space)
+
+	return sweepPairs(left, right, func(x, y entry) bool {
+		if n.leaf {
+			return fn(x.obj, y.obj)
+		}
+		return join(x.child, x.bb, y.child, y.bb, fn)
+	})
+}
+
+// SelfJoin calls fn once for every unordered pair of distinct intersecting
+// objects in the tree, until fn returns false. The pairs within a subtree are
+// found recursively, and the pairs across sibling subtrees by joining the
+// siblings with intersecting bounding boxes.
+func (tree *Rtree) SelfJoin(fn func(a, b Spatial) bool) {
+	tree.selfJoin(tree.root, fn)
+}
+
+// selfJoin reports the pairs of intersecting objects in the subtree of n. It
+// returns false if fn stopped the join.
+func (tree *Rtree) selfJoin(n *node, fn func(a, b Spatial) bool) bool {
+	if !n.leaf {
+		for _, e := range n.entries {
+			if !tree.selfJoin(e.child, fn) {
+				return false
+			}
+		}
+	}
+
+	entries := make([]entry, len(n.entries))
+	copy(entries, n.entries)
+	sortByLowerSide(entries)
+	return sweepSelfPairs(entries, func(x, y entry) bool {
+		if n.leaf {
+			return fn(x.obj, y.obj)
+		}
+		return join(x.child, x.bb, y.child, y.bb, fn)
+	})
+}
+
+// restrictEntries returns the entries intersecting space, sorted by the lower
+// side of their bounding boxes in the first dimension.
+func restrictEntries(entries []entry, space Rect) []entry {
+	var restricted []entry
+	for _, e := range entries {
+		if intersect(e.bb, space) {
+			restricted = append(restricted, e)
+		}
+	}
+	sortByLowerSide(restricted)
+	return restricted
+}
+
+// sortByLowerSide sorts entries by the lower side of their bounding boxes in
+// the first dimension.
+func sortByLowerSide(entries []entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].bb.p[0] < entries[j].bb.p[0]
+	})
+}
+
+// sweepPairs calls fn for every pair of intersecting entries from left and
+// right, which are sorted by the lower side of their bounding boxes in the
+// first dimension, until fn returns false. It returns false if fn did.
+func sweepPairs(left, right []entry, fn func(x, y entry) bool) bool {
+	i, j := 0, 0
+	for i < len(left) && j < len(right) {
+		if left[i].bb.p[0] <= right[j].bb.p[0] {
+			x := left[i]
+			for k := j; k < len(right) && right[k].bb.p[0] < x.bb.q[0]; k++ {
+				if intersect(x.bb, right[k].bb) && !fn(x, right[k]) {
+					return false
+				}
+			}
+			i++
+		} else {
+			y := right[j]
+			for k := i; k < len(left) && left[k].bb.p[0] < y.bb.q[0]; k++ {
+				if intersect(left[k].bb, y.bb) && !fn(left[k], y) {
+					return false
+				}
+			}
+			j++
+		}
+	}
+	return true
+}
+
+// sweepSelfPairs calls fn for every pair of distinct intersecting entries,
+// which are sorted by the lower side of their bounding boxes in the first
+// dimension, until fn returns false. It returns false if fn did.
+func sweepSelfPairs(entries []entry, fn func(x, y entry) bool) bool {
+	for i, x := range entries {
+		for _, y := range entries[i+1:] {
+			if y.bb.p[0] >= x.bb.q[0] {
+				break
+			}
+			if intersect(x.bb, y.bb) && !fn(x, y) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Pair is a pair of objects found by ClosestPairs, one from each tree, along
+// with the distance between their bounding boxes.
+type Pair struct {
+	A, B Spatial
+	Dist float64
+}
+
+// ClosestPairs returns the k pairs of objects, one from a and one from b,
+// whose bounding boxes are closest to each other in the Euclidean metric, in
+// increasing order of distance. Pairs of nodes are visited in increasing
+// order of the distance between their bounding boxes, and pairs farther apart
+// than the k closest pairs of objects found so far are pruned. The trees must
+// have the same dimension.
+//
+// Implemented per "Closest Pair Queries in Spatial Databases" by A. Corral,
+// Y. Manolopoulos, Y. Theodoridis and M. Vassilakopoulos, SIGMOD,
+// p. 189-200, 2000.
+func ClosestPairs(a, b *Rtree, k int) []Pair {
+	if a.Dim != b.Dim {
+		panic(DimError{a.Dim, b.Dim})
+	}
+	pairs := []Pair{}
+	if a.size == 0 || b.size == 0 || k <= 0 {
+		return pairs
+	}
+
+	q := &closestPairsQueue{k: k}
+	q.push(
+		entry{bb: a.root.computeBoundingBox(), child: a.root},
+		entry{bb: b.root.computeBoundingBox(), child: b.root},
+	)
+	for q.Len() > 0 && len(pairs) < k {
+		item := heap.Pop(q).(closestPairsItem)
+		x, y := item.x, item.y
+		xlevel, ylevel := entryLevel(x), entryLevel(y)
+		switch {
+		case xlevel == 0 && ylevel == 0:
+			pairs = append(pairs, Pair{x.obj, y.obj, math.Sqrt(item.dist)})
+		case xlevel > ylevel:
+			for _, e := range x.child.entries {
+				q.push(e, y)
+			}
+		case ylevel > xlevel:
+			for _, e := range y.child.entries {
+				q.push(x, e)
+			}
+		default:
+			for _, ex := range x.child.entries {
+				for _, ey := range y.child.entries {
+					q.push(ex, ey)
+				}
+			}
+		}
+	}
+	return pairs
+}
+
+// entryLevel returns the level of the child of e, or zero for an object.
+func entryLevel(e entry) int {
+	if e.child == nil {
+		return 0
+	}
+	return e.child.level
+}
+
+// closestPairsItem is a pair of entries in the queue of ClosestPairs, keyed
+// by the squared distance between their bounding boxes.
+type closestPairsItem struct {
+	x, y entry
+	dist float64
+}
+
+// closestPairsQueue is a min-heap of pairs of entries. It also keeps the
+// squared distances of the k closest pairs of objects pushed so far, which
+// bound the distance of the pairs worth pushing.
+type closestPairsQueue struct {
+	items []closestPairsItem
+	k     int
+	dists []float64
+}
+
+// push adds the pair of x and y to the queue unless it is farther apart than
+// the k closest pairs of objects seen so far.
+func (q *closestPairsQueue) push(x, y entry) {
+	dist := rectMinDist(x.bb, y.bb)
+	if len(q.dists) == q.k && dist > q.dists[q.k-1] {
+		return
+	}
+	if x.child == nil && y.child == nil {
+		i := sort.SearchFloat64s(q.dists, dist)
+		if len(q.dists) < q.k {
+			q.dists = append(q.dists, 0)
+		}
+		copy(q.dists[i+1:], q.dists[i:])
+		q.dists[i] = dist
+	}
+	heap.Push(q, closestPairsItem{x, y, dist})
+}
+
+func (q closestPairsQueue) Len() int { return len(q.items) }
+
+func (q closestPairsQueue) Less(i, j int) bool {
+	return q.items[i].dist < q.items[j].dist
+}
+
+func (q closestPairsQueue) Swap(i, j int) {
+	q.items[i], q.items[j] = q.items[j], q.items[i]
+}
+
+func (q *closestPairsQueue) Push(x interface{}) {
+	q.items = append(q.items, x.(closestPairsItem))
+}
+
+func (q *closestPairsQueue) Pop() interface{} {
+	item := q.items[len(q.items)-1]
+	q.items[len(q.items)-1] = closestPairsItem{}
+	q.items = q.items[:len(q.items)-1]
+	return item
+}
@@ -0,0 +1,229 @@
+package rtreego
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+)
+
+// joinPairs returns the pairs of objects reported by Join as strings.
+func joinPairs(a, b *Rtree) map[string]int {
+	pairs := map[string]int{}
+	Join(a, b, func(x, y Spatial) bool {
+		pairs[fmt.Sprintf("%p-%p", x, y)]++
+		return true
+	})
+	return pairs
+}
+
+// bruteForcePairs returns the pairs of intersecting objects as strings.
+func bruteForcePairs(xs, ys []Spatial) map[string]int {
+	pairs := map[string]int{}
+	for _, x := range xs {
+		for _, y := range ys {
+			if intersect(x.Bounds(), y.Bounds()) {
+				pairs[fmt.Sprintf("%p-%p", x, y)]++
+			}
+		}
+	}
+	return pairs
+}
+
+func TestJoin(t *testing.T) {
+	xs := randomRects(300, 17)
+	ys := randomRects(40, 18)
+	for _, y := range ys {
+		// make the objects of the second tree larger
+		r := y.(*Rect)
+		for i := range r.q {
+			r.q[i] += 3
+		}
+	}
+	expected := bruteForcePairs(xs, ys)
+
+	for _, atc := range tests(2, 3, 6, xs...) {
+		for _, btc := range tests(2, 2, 4, ys...) {
+			t.Run(atc.name+"-"+btc.name, func(t *testing.T) {
+				a, b := atc.build(), btc.build()
+				pairs := joinPairs(a, b)
+				if len(pairs) != len(expected) {
+					t.Errorf("Join reported %d pairs, expected %d", len(pairs), len(expected))
+				}
+				for pair, count := range pairs {
+					if count != 1 || expected[pair] != 1 {
+						t.Errorf("Join reported pair %s %d times, expected %d", pair, count, expected[pair])
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestJoinDifferentHeights(t *testing.T) {
+	xs := randomRects(500, 19)
+	ys := randomRects(5, 20)
+	expected := bruteForcePairs(ys, xs)
+
+	a, b := NewTree(2, 2, 10, ys...), NewTree(2, 2, 3, xs...)
+	if a.Depth() >= b.Depth() {
+		t.Fatalf("expected the first tree to be lower")
+	}
+	pairs := joinPairs(a, b)
+	if len(pairs) != len(expected) {
+		t.Errorf("Join reported %d pairs, expected %d", len(pairs), len(expected))
+	}
+	for pair := range pairs {
+		if expected[pair] != 1 {
+			t.Errorf("Join reported unexpected pair %s", pair)
+		}
+	}
+}
+
+func TestJoinStop(t *testing.T) {
+	xs := randomRects(100, 21)
+	a, b := NewTree(2, 3, 6, xs...), NewTree(2, 3, 6, xs...)
+
+	count := 0
+	Join(a, b, func(x, y Spatial) bool {
+		count++
+		return count < 5
+	})
+	if count != 5 {
+		t.Errorf("expected Join to stop after 5 pairs, got %d", count)
+	}
+}
+
+func TestJoinEmpty(t *testing.T) {
+	a, b := NewTree(2, 3, 6, randomRects(10, 22)...), NewTree(2, 3, 6)
+	Join(a, b, func(x, y Spatial) bool {
+		t.Errorf("expected no pairs, got %v and %v", x, y)
+		return true
+	})
+}
+
+func TestJoinDimError(t *testing.T) {
+	defer func() {
+		if _, ok := recover().(DimError); !ok {
+			t.Errorf("expected DimError when joining trees of different dimensions")
+		}
+	}()
+	Join(NewTree(2, 3, 6), NewTree(3, 3, 6), func(x, y Spatial) bool { return true })
+}
+
+func TestSelfJoin(t *testing.T) {
+	things := randomRects(400, 23)
+	for _, thing := range things[:20] {
+		// add some larger objects spanning several nodes
+		r := thing.(*Rect)
+		for i := range r.q {
+			r.q[i] += 10
+		}
+	}
+
+	expected := map[string]int{}
+	for i, x := range things {
+		for _, y := range things[i+1:] {
+			if intersect(x.Bounds(), y.Bounds()) {
+				expected[fmt.Sprintf("%p-%p", x, y)]++
+			}
+		}
+	}
+
+	for _, tc := range tests(2, 3, 6, things...) {
+		t.Run(tc.name, func(t *testing.T) {
+			rt := tc.build()
+
+			pairs := map[string]int{}
+			rt.SelfJoin(func(a, b Spatial) bool {
+				if a == b {
+					t.Errorf("SelfJoin reported %v with itself", a)
+				}
+				pair := fmt.Sprintf("%p-%p", a, b)
+				if _, ok := expected[pair]; !ok {
+					pair = fmt.Sprintf("%p-%p", b, a)
+				}
+				pairs[pair]++
+				return true
+			})
+
+			if len(pairs) != len(expected) {
+				t.Errorf("SelfJoin reported %d pairs, expected %d", len(pairs), len(expected))
+			}
+			for pair, count := range pairs {
+				if count != 1 || expected[pair] != 1 {
+					t.Errorf("SelfJoin reported pair %s %d times, expected %d", pair, count, expected[pair])
+				}
+			}
+		})
+	}
+}
+
+func TestSelfJoinStop(t *testing.T) {
+	things := randomRects(200, 24)
+	for _, thing := range things {
+		r := thing.(*Rect)
+		for i := range r.q {
+			r.q[i] += 5
+		}
+	}
+	rt := NewTree(2, 3, 6, things...)
+
+	count := 0
+	rt.SelfJoin(func(a, b Spatial) bool {
+		count++
+		return count < 3
+	})
+	if count != 3 {
+		t.Errorf("expected SelfJoin to stop after 3 pairs, got %d", count)
+	}
+}
+
+func TestClosestPairs(t *testing.T) {
+	xs := randomRects(150, 25)
+	ys := randomRects(80, 26)
+
+	var expected []Pair
+	for _, x := range xs {
+		for _, y := range ys {
+			expected = append(expected, Pair{x, y, math.Sqrt(rectMinDist(x.Bounds(), y.Bounds()))})
+		}
+	}
+	sort.SliceStable(expected, func(i, j int) bool {
+		return expected[i].Dist < expected[j].Dist
+	})
+
+	for _, atc := range tests(2, 3, 6, xs...) {
+		for _, btc := range tests(2, 2, 4, ys...) {
+			t.Run(atc.name+"-"+btc.name, func(t *testing.T) {
+				a, b := atc.build(), btc.build()
+				pairs := ClosestPairs(a, b, 20)
+				if len(pairs) != 20 {
+					t.Fatalf("ClosestPairs returned %d pairs, expected 20", len(pairs))
+				}
+				for i, pair := range pairs {
+					if math.Abs(pair.Dist-expected[i].Dist) > EPS {
+						t.Errorf("ClosestPairs distance at index %d: %v != %v", i, pair.Dist, expected[i].Dist)
+					}
+					if d := math.Sqrt(rectMinDist(pair.A.Bounds(), pair.B.Bounds())); math.Abs(pair.Dist-d) > EPS {
+						t.Errorf("ClosestPairs returned distance %v for pair at distance %v", pair.Dist, d)
+					}
+					if !contains(pair.A, xs) || !contains(pair.B, ys) {
+						t.Errorf("ClosestPairs returned pair in the wrong order: %v", pair)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestClosestPairsAll(t *testing.T) {
+	xs, ys := randomRects(5, 27), randomRects(4, 28)
+	a, b := NewTree(2, 2, 3, xs...), NewTree(2, 2, 3, ys...)
+	if pairs := ClosestPairs(a, b, 100); len(pairs) != len(xs)*len(ys) {
+		t.Errorf("expected ClosestPairs to return all %d pairs, got %d", len(xs)*len(ys), len(pairs))
+	}
+	if pairs := ClosestPairs(a, NewTree(2, 2, 3), 10); len(pairs) != 0 {
+		t.Errorf("expected no pairs with an empty tree, got %v", pairs)
+	}
+}
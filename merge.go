@@ -0,0 +1,69 @@
+// Copyright 2012 Daniel Connelly.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtreego
+
+import "errors"
+
+// ErrIncompatibleTrees is returned when combining trees whose branching
+// factors or insertion modes differ.
+var ErrIncompatibleTrees = errors.New("rtreego: incompatible trees")
+
+// Merge moves all objects of other into tree, leaving other empty. Instead
+// of reinserting every object, the lower of the two trees is grafted into
+// the higher one as a subtree at the level matching its height.
+//
+// Both trees must have the same dimension, branching factors and insertion
+// mode; Hilbert R-trees must also map the same region onto the curve. A tree
+// cannot be merged into itself.
+func (tree *Rtree) Merge(other *Rtree) error {
+	if tree == other {
+		return ErrIncompatibleTrees
+	}
+	if tree.Dim != other.Dim {
+		return &DimError{tree.Dim, other.Dim}
+	}
+	if tree.MinChildren != other.MinChildren ||
+		tree.MaxChildren != other.MaxChildren ||
+		tree.insertMode != other.insertMode {
+		return ErrIncompatibleTrees
+	}
+	if tree.insertMode == HilbertInsert && !tree.hilbert.bounds.Equal(other.hilbert.bounds) {
+		return ErrIncompatibleTrees
+	}
+	if tree.readOnly || other.readOnly {
+		return ErrReadOnly
+	}
+
+	// the nodes of other may be shared with its snapshots, so the tree must
+	// not consider them its own
+	if other.gen != tree.gen {
+		tree.gen = nextGeneration()
+	}
+
+	if other.size > 0 {
+		root, height := other.root, other.height
+		if tree.size == 0 {
+			tree.root, tree.height = root, height
+		} else {
+			if height > tree.height {
+				root, tree.root = tree.root, root
+				height, tree.height = tree.height, height
+			}
+			tree.graft(root, height)
+		}
+		tree.size += other.size
+		tree.distanceObjects = tree.distanceObjects || other.distanceObjects
+	}
+
+	other.root = &node{
+		entries: []entry{},
+		leaf:    true,
+		level:   1,
+		gen:     other.gen,
+	}
+	other.height = 1
+	other.size = 0
+	return nil
+}
@@ -0,0 +1,116 @@
+package rtreego
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	things := randomRects(600, 9)
+	for _, tc := range []struct {
+		a, b int
+	}{
+		{0, 0},
+		{0, 50},
+		{50, 0},
+		{3, 300},
+		{300, 3},
+		{200, 400},
+		{400, 200},
+		{300, 300},
+	} {
+		t.Run(fmt.Sprintf("a=%d-b=%d", tc.a, tc.b), func(t *testing.T) {
+			a := NewTree(2, 2, 5, things[:tc.a]...)
+			b := NewTree(2, 2, 5, things[tc.a:tc.a+tc.b]...)
+			if err := a.Merge(b); err != nil {
+				t.Fatalf("Merge failed: %v", err)
+			}
+
+			verify(t, a)
+			verify(t, b)
+			if a.Size() != tc.a+tc.b {
+				t.Errorf("expected size %d, got %d", tc.a+tc.b, a.Size())
+			}
+			if b.Size() != 0 || len(b.root.entries) != 0 {
+				t.Errorf("expected merged tree to be empty, got size %d", b.Size())
+			}
+			for _, thing := range things[:tc.a+tc.b] {
+				if !contains(thing, a.SearchIntersect(thing.Bounds())) {
+					t.Fatalf("SearchIntersect failed to find %v", thing)
+				}
+			}
+			for _, thing := range things[:tc.a+tc.b] {
+				if !a.Delete(thing) {
+					t.Fatalf("Delete failed to delete %v", thing)
+				}
+			}
+			verify(t, a)
+		})
+	}
+}
+
+func TestMergeKeepsSubtrees(t *testing.T) {
+	things := randomRects(300, 10)
+	a := NewTree(2, 2, 5, things[:250]...)
+	b := NewTree(2, 2, 5, things[250:]...)
+	bRoot := b.root
+
+	if err := a.Merge(b); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	for _, thing := range things[250:] {
+		n := a.findLeaf(a.root, thing, defaultComparator)
+		for n != bRoot && n.parent != nil {
+			n = n.parent
+		}
+		if n != bRoot {
+			t.Fatalf("expected %v to stay below the root of the merged tree", thing)
+		}
+	}
+}
+
+func TestMergeHilbert(t *testing.T) {
+	things := randomRects(500, 11)
+	opts := Options{
+		InsertMode:    HilbertInsert,
+		HilbertBounds: mustRect(Point{0, 0}, []float64{100, 100}),
+	}
+	a := NewTreeWithOptions(2, 2, 6, opts, things[:250]...)
+	b := NewTreeWithOptions(2, 2, 6, opts, things[250:]...)
+	if err := a.Merge(b); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	verify(t, a)
+	verifyHilbertOrder(t, a, a.root)
+	for _, thing := range things {
+		if !contains(thing, a.SearchIntersect(thing.Bounds())) {
+			t.Fatalf("SearchIntersect failed to find %v", thing)
+		}
+	}
+}
+
+func TestMergeIncompatible(t *testing.T) {
+	a := NewTree(2, 2, 5)
+	if err := a.Merge(NewTree(3, 2, 5)); err == nil {
+		t.Errorf("expected an error for mismatched dimensions")
+	} else if _, ok := err.(*DimError); !ok {
+		t.Errorf("expected a DimError, got %v", err)
+	}
+	if err := a.Merge(NewTree(2, 2, 6)); err != ErrIncompatibleTrees {
+		t.Errorf("expected ErrIncompatibleTrees for mismatched branching factors, got %v", err)
+	}
+	if err := a.Merge(NewTreeWithOptions(2, 2, 5, Options{InsertMode: RStarInsert})); err != ErrIncompatibleTrees {
+		t.Errorf("expected ErrIncompatibleTrees for mismatched insertion modes, got %v", err)
+	}
+	if err := a.Merge(a); err != ErrIncompatibleTrees {
+		t.Errorf("expected ErrIncompatibleTrees when merging a tree into itself, got %v", err)
+	}
+
+	bounds := mustRect(Point{0, 0}, []float64{1, 1})
+	h1 := NewTreeWithOptions(2, 2, 5, Options{InsertMode: HilbertInsert, HilbertBounds: bounds})
+	h2 := NewTreeWithOptions(2, 2, 5, Options{InsertMode: HilbertInsert, HilbertBounds: bounds.p.ToRect(1)})
+	if err := h1.Merge(h2); err != ErrIncompatibleTrees {
+		t.Errorf("expected ErrIncompatibleTrees for mismatched Hilbert bounds, got %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2012 Daniel Connelly.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtreego
+
+import (
+	"math"
+)
+
+// Metric measures the distances used by nearest-neighbor and radius queries.
+type Metric interface {
+	// Dist returns the distance between p and q.
+	Dist(p, q Point) float64
+
+	// MinDist returns the distance from p to the closest point of r, which
+	// is zero if r contains p. It is used to prune the search, so it must
+	// never be larger than the distance from p to any point of r.
+	MinDist(p Point, r Rect) float64
+}
+
+// Euclidean is the Euclidean (L2) metric, which is the default.
+type Euclidean struct{}
+
+// Dist implements Metric.
+func (Euclidean) Dist(p, q Point) float64 {
+	return p.dist(q)
+}
+
+// MinDist implements Metric.
+func (Euclidean) MinDist(p Point, r Rect) float64 {
+	return math.Sqrt(p.minDist(r))
+}
+
+// Manhattan is the Manhattan (L1) metric, the sum of the distances along
+// each dimension.
+type Manhattan struct{}
+
+// Dist implements Metric.
+func (Manhattan) Dist(p, q Point) float64 {
+	if len(p) != len(q) {
+		panic(DimError{len(p), len(q)})
+	}
+	sum := 0.0
+	for i := range p {
+		sum += math.Abs(p[i] - q[i])
+	}
+	return sum
+}
+
+// MinDist implements Metric.
+func (Manhattan) MinDist(p Point, r Rect) float64 {
+	if len(p) != len(r.p) {
+		panic(DimError{len(p), len(r.p)})
+	}
+	sum := 0.0
+	for i := range p {
+		sum += r.gap(p, i)
+	}
+	return sum
+}
+
+// Chebyshev is the Chebyshev (L∞) metric, the largest of the distances along
+// each dimension.
+type Chebyshev struct{}
+
+// Dist implements Metric.
+func (Chebyshev) Dist(p, q Point) float64 {
+	if len(p) != len(q) {
+		panic(DimError{len(p), len(q)})
+	}
+	max := 0.0
+	for i := range p {
+		max = math.Max(max, math.Abs(p[i]-q[i]))
+	}
+	return max
+}
+
+// MinDist implements Metric.
+func (Chebyshev) MinDist(p Point, r Rect) float64 {
+	if len(p) != len(r.p) {
+		panic(DimError{len(p), len(r.p)})
+	}
+	max := 0.0
+	for i := range p {
+		max = math.Max(max, r.gap(p, i))
+	}
+	return max
+}
+
+// WeightedEuclidean is the Euclidean metric with the squared distance along
+// each dimension scaled by a non-negative weight. Weights must have one
+// weight per dimension.
+type WeightedEuclidean struct {
+	Weights []float64
+}
+
+// Dist implements Metric.
+func (m WeightedEuclidean) Dist(p, q Point) float64 {
+	if len(p) != len(q) {
+		panic(DimError{len(p), len(q)})
+	}
+	if len(m.Weights) != len(p) {
+		panic(DimError{len(m.Weights), len(p)})
+	}
+	sum := 0.0
+	for i := range p {
+		d := p[i] - q[i]
+		sum += m.Weights[i] * d * d
+	}
+	return math.Sqrt(sum)
+}
+
+// MinDist implements Metric.
+func (m WeightedEuclidean) MinDist(p Point, r Rect) float64 {
+	if len(p) != len(r.p) {
+		panic(DimError{len(p), len(r.p)})
+	}
+	if len(m.Weights) != len(p) {
+		panic(DimError{len(m.Weights), len(p)})
+	}
+	sum := 0.0
+	for i := range p {
+		d := r.gap(p, i)
+		sum += m.Weights[i] * d * d
+	}
+	return math.Sqrt(sum)
+}
+
+// gap returns the distance from p to r along dimension i, which is zero if
+// the coordinate of p lies between the sides of r.
+func (r Rect) gap(p Point, i int) float64 {
+	if p[i] < r.p[i] {
+		return r.p[i] - p[i]
+	} else if p[i] > r.q[i] {
+		return p[i] - r.q[i]
+	}
+	return 0
+}
+
+// minDist returns the key by which the distance from p to r is ranked in
+// queries: the squared distance for the default Euclidean metric, which saves
+// square roots, and the distance itself for any other metric. In a tree with
+// periodic boundaries, the distance is measured from the closest image of p.
+func (tree *Rtree) minDist(p Point, r Rect) float64 {
+	if tree.periods != nil {
+		p = tree.closestImage(p, r)
+	}
+	if tree.metric == nil {
+		return p.minDist(r)
+	}
+	return tree.metric.MinDist(p, r)
+}
+
+// objectDist returns the key of the distance from p to the object of a leaf
+// entry, using DistanceTo if the object implements DistanceSpatial.
+func (tree *Rtree) objectDist(p Point, e entry) float64 {
+	if !tree.distanceObjects {
+		return tree.minDist(p, e.bb)
+	}
+	if obj, ok := e.obj.(DistanceSpatial); ok {
+		if tree.periods != nil {
+			p = tree.closestImage(p, e.bb)
+		}
+		return tree.distKey(obj.DistanceTo(p))
+	}
+	return tree.minDist(p, e.bb)
+}
+
+// distKey converts a distance into its key.
+func (tree *Rtree) distKey(d float64) float64 {
+	if tree.metric == nil {
+		return d * d
+	}
+	return d
+}
+
+// keyDist converts a key back into a distance.
+func (tree *Rtree) keyDist(key float64) float64 {
+	if tree.metric == nil {
+		return math.Sqrt(key)
+	}
+	return key
+}
@@ -0,0 +1,109 @@
+package rtreego
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+)
+
+var metrics = []Metric{
+	Euclidean{},
+	Manhattan{},
+	Chebyshev{},
+	WeightedEuclidean{Weights: []float64{4, 0.25}},
+}
+
+func TestMetricDist(t *testing.T) {
+	p, q := Point{1, 2}, Point{4, -2}
+	expected := []float64{5, 7, 4, math.Sqrt(4*9 + 0.25*16)}
+	for i, m := range metrics {
+		if d := m.Dist(p, q); math.Abs(d-expected[i]) > EPS {
+			t.Errorf("%T: expected Dist(%v, %v) == %v, got %v", m, p, q, expected[i], d)
+		}
+	}
+}
+
+func TestMetricMinDist(t *testing.T) {
+	p := Point{1, 2}
+	r := mustRect(Point{4, -6}, []float64{2, 4})
+	expected := []float64{5, 7, 4, math.Sqrt(4*9 + 0.25*16)}
+	for i, m := range metrics {
+		if d := m.MinDist(p, r); math.Abs(d-expected[i]) > EPS {
+			t.Errorf("%T: expected MinDist(%v, %v) == %v, got %v", m, p, r, expected[i], d)
+		}
+	}
+
+	inside := Point{5, -3}
+	for _, m := range metrics {
+		if d := m.MinDist(inside, r); d != 0 {
+			t.Errorf("%T: expected MinDist(%v, %v) == 0, got %v", m, inside, r, d)
+		}
+	}
+}
+
+func TestMetricDimError(t *testing.T) {
+	r := mustRect(Point{0, 0}, []float64{1, 1})
+	expectDimError := func(m Metric, p Point) {
+		defer func() {
+			if _, ok := recover().(DimError); !ok {
+				t.Errorf("%T: expected DimError for %v", m, p)
+			}
+		}()
+		m.MinDist(p, r)
+	}
+
+	for _, m := range metrics {
+		expectDimError(m, Point{1, 2, 3})
+	}
+	expectDimError(WeightedEuclidean{Weights: []float64{1}}, Point{1, 2})
+}
+
+func TestMetricQueries(t *testing.T) {
+	things := randomRects(200, 13)
+	p := Point{30, 70}
+	for _, m := range metrics {
+		t.Run(fmt.Sprintf("%T", m), func(t *testing.T) {
+			rt := NewTreeWithOptions(2, 3, 6, Options{Metric: m}, things...)
+
+			expected := append([]Spatial{}, things...)
+			sort.SliceStable(expected, func(i, j int) bool {
+				return m.MinDist(p, expected[i].Bounds()) < m.MinDist(p, expected[j].Bounds())
+			})
+
+			objs, dists := rt.NearestNeighborsWithDistances(10, p)
+			for i := range objs {
+				if objs[i] != expected[i] {
+					t.Errorf("NearestNeighbors failed at index %d: %v != %v", i, objs[i], expected[i])
+				}
+				if d := m.MinDist(p, expected[i].Bounds()); math.Abs(dists[i]-d) > EPS {
+					t.Errorf("NearestNeighborsWithDistances distance at index %d: %v != %v", i, dists[i], d)
+				}
+			}
+
+			if obj := rt.NearestNeighbor(p); obj != expected[0] {
+				t.Errorf("NearestNeighbor failed: %v != %v", obj, expected[0])
+			}
+
+			it := rt.NearestIterator(p)
+			for i := 0; i < 10; i++ {
+				if obj, _, _ := it.Next(); obj != expected[i] {
+					t.Errorf("NearestIterator failed at index %d: %v != %v", i, obj, expected[i])
+				}
+			}
+
+			radius := 12.0
+			var within []Spatial
+			for _, thing := range things {
+				if m.MinDist(p, thing.Bounds()) <= radius {
+					within = append(within, thing)
+				}
+			}
+			results := rt.SearchWithinDistance(p, radius)
+			if len(results) != len(within) {
+				t.Errorf("SearchWithinDistance(%v, %v) returned %d objects, expected %d", p, radius, len(results), len(within))
+			}
+			ensureDisorderedSubset(t, results, within)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2012 Daniel Connelly.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtreego
+
+import (
+	"container/heap"
+)
+
+// NearestIterator returns the objects of a tree one at a time in increasing
+// order of their distance from a point. Only as much of the tree is visited
+// as is needed to produce the objects returned so far, so it is well suited
+// to queries where the number of neighbors is not known in advance.
+//
+// The tree must not be modified while an iterator is in use.
+type NearestIterator struct {
+	tree  *Rtree
+	p     Point
+	queue nearestQueue
+}
+
+// NearestIterator returns an iterator over the objects in the tree, ordered
+// by increasing distance from p.
+//
+// Implemented per "Distance Browsing in Spatial Databases" by G. R. Hjaltason
+// and H. Samet, ACM TODS 24(2), p. 265-318, 1999.
+func (tree *Rtree) NearestIterator(p Point) *NearestIterator {
+	it := &NearestIterator{tree: tree, p: p}
+	it.push(tree.root)
+	return it
+}
+
+// Next returns the next closest object and its distance from the point in the
+// tree's metric, which is the distance to the closest point of its bounding
+// box unless the object implements DistanceSpatial. The last result is false
+// once all objects have been returned.
+func (it *NearestIterator) Next() (Spatial, float64, bool) {
+	for it.queue.Len() > 0 {
+		item := heap.Pop(&it.queue).(nearestItem)
+		if item.child == nil {
+			return item.obj, it.tree.keyDist(item.dist), true
+		}
+		it.push(item.child)
+	}
+	return nil, 0, false
+}
+
+// push adds the entries of n to the queue.
+func (it *NearestIterator) push(n *node) {
+	for _, e := range n.entries {
+		item := nearestItem{entry: e}
+		if n.leaf {
+			item.dist = it.tree.objectDist(it.p, e)
+		} else {
+			item.dist = it.tree.minDist(it.p, e.bb)
+		}
+		heap.Push(&it.queue, item)
+	}
+}
+
+// nearestItem is an entry of a node or an object in the queue of a
+// NearestIterator, keyed by its distance from the query point as returned by
+// minDist.
+type nearestItem struct {
+	entry
+	dist float64
+}
+
+// nearestQueue is a min-heap of nearestItems. At equal distances objects come
+// before nodes, so they are returned without expanding further nodes.
+type nearestQueue []nearestItem
+
+func (q nearestQueue) Len() int { return len(q) }
+
+func (q nearestQueue) Less(i, j int) bool {
+	if q[i].dist != q[j].dist {
+		return q[i].dist < q[j].dist
+	}
+	return q[i].child == nil && q[j].child != nil
+}
+
+func (q nearestQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *nearestQueue) Push(x interface{}) {
+	*q = append(*q, x.(nearestItem))
+}
+
+func (q *nearestQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	old[len(old)-1] = nearestItem{}
+	*q = old[:len(old)-1]
+	return item
+}
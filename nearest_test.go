@@ -0,0 +1,137 @@
+package rtreego
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+func TestNearestIterator(t *testing.T) {
+	things := randomRects(200, 11)
+	p := Point{40, 60}
+
+	expected := append([]Spatial{}, things...)
+	sort.Sort(byMinDist{expected, p})
+
+	for _, tc := range tests(2, 3, 6, things...) {
+		t.Run(tc.name, func(t *testing.T) {
+			rt := tc.build()
+
+			it := rt.NearestIterator(p)
+			var objs []Spatial
+			prev := 0.0
+			for {
+				obj, dist, ok := it.Next()
+				if !ok {
+					break
+				}
+				if dist < prev {
+					t.Errorf("NearestIterator returned distance %v after %v", dist, prev)
+				}
+				if expected := math.Sqrt(p.minDist(obj.Bounds())); math.Abs(dist-expected) > EPS {
+					t.Errorf("NearestIterator returned distance %v for %v, expected %v", dist, obj, expected)
+				}
+				prev = dist
+				objs = append(objs, obj)
+			}
+
+			if len(objs) != len(things) {
+				t.Fatalf("NearestIterator returned %d objects, expected %d", len(objs), len(things))
+			}
+			for i := range objs {
+				if p.minDist(objs[i].Bounds()) != p.minDist(expected[i].Bounds()) {
+					t.Errorf("NearestIterator failed at index %d: %v != %v", i, objs[i], expected[i])
+				}
+			}
+			ensureDisorderedSubset(t, objs, things)
+
+			if _, _, ok := it.Next(); ok {
+				t.Errorf("expected exhausted NearestIterator to stay exhausted")
+			}
+		})
+	}
+}
+
+func TestNearestIteratorMatchesNearestNeighbors(t *testing.T) {
+	things := randomRects(100, 12)
+	rt := NewTree(2, 3, 6, things...)
+	p := Point{10, 90}
+
+	neighbors := rt.NearestNeighbors(10, p)
+	it := rt.NearestIterator(p)
+	for i := range neighbors {
+		obj, _, ok := it.Next()
+		if !ok || obj != neighbors[i] {
+			t.Errorf("NearestIterator failed at index %d: %v != %v", i, obj, neighbors[i])
+		}
+	}
+}
+
+func TestNearestIteratorEmpty(t *testing.T) {
+	rt := NewTree(2, 3, 6)
+	if obj, _, ok := rt.NearestIterator(Point{0, 0}).Next(); ok {
+		t.Errorf("expected empty tree to return no objects, got %v", obj)
+	}
+}
+
+// segment is a line segment that computes its exact distance to a point.
+type segment struct {
+	a, b Point
+}
+
+func (s *segment) Bounds() Rect {
+	r, err := NewRectFromPoints(s.a, s.b)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func (s *segment) DistanceTo(p Point) float64 {
+	ab := Point{s.b[0] - s.a[0], s.b[1] - s.a[1]}
+	ap := Point{p[0] - s.a[0], p[1] - s.a[1]}
+	t := (ap[0]*ab[0] + ap[1]*ab[1]) / (ab[0]*ab[0] + ab[1]*ab[1])
+	t = math.Max(0, math.Min(1, t))
+	return p.dist(Point{s.a[0] + t*ab[0], s.a[1] + t*ab[1]})
+}
+
+func TestNearestNeighborsDistanceSpatial(t *testing.T) {
+	// the bounding box of the diagonal contains p, but the diagonal itself
+	// is farther away than the other segments
+	diagonal := &segment{Point{0, 0}, Point{10, 10}}
+	near := &segment{Point{2, 8}, Point{2, 9}}
+	far := &segment{Point{-3, 8}, Point{-3, 9}}
+	things := []Spatial{diagonal, near, far}
+	for i := 0; i < 10; i++ {
+		x := 20 + float64(i)
+		things = append(things, &segment{Point{x, 0}, Point{x, 1}})
+	}
+	p := Point{1, 8}
+
+	for _, tc := range tests(2, 2, 3, things...) {
+		t.Run(tc.name, func(t *testing.T) {
+			rt := tc.build()
+
+			if obj := rt.NearestNeighbor(p); obj != near {
+				t.Errorf("NearestNeighbor(%v) = %v, expected %v", p, obj, near)
+			}
+
+			expected := []Spatial{near, far, diagonal}
+			objs, dists := rt.NearestNeighborsWithDistances(3, p)
+			ensureOrderedSubset(t, objs, expected)
+			for i, obj := range objs {
+				if d := obj.(*segment).DistanceTo(p); math.Abs(dists[i]-d) > EPS {
+					t.Errorf("NearestNeighborsWithDistances distance at index %d: %v != %v", i, dists[i], d)
+				}
+			}
+
+			it := rt.NearestIterator(p)
+			for i, exp := range expected {
+				obj, dist, _ := it.Next()
+				if obj != exp || math.Abs(dist-exp.(*segment).DistanceTo(p)) > EPS {
+					t.Errorf("NearestIterator failed at index %d: %v (%v) != %v", i, obj, dist, exp)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2012 Daniel Connelly.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtreego
+
+import (
+	"fmt"
+	"math"
+)
+
+// newPeriods returns the periods of the dimensions of a tree, adding a period
+// of 360 degrees to the first dimension if wrapLongitude is set.
+func newPeriods(dim int, periods []float64, wrapLongitude bool) []float64 {
+	if periods != nil && len(periods) != dim {
+		panic(DimError{dim, len(periods)})
+	}
+	result := make([]float64, dim)
+	for i, period := range periods {
+		if period < 0 {
+			panic(fmt.Errorf("rtreego: negative period %v", period))
+		}
+		result[i] = period
+	}
+	if wrapLongitude {
+		result[0] = 360
+	}
+	return result
+}
+
+// images returns the copies of bb shifted by whole periods along the periodic
+// dimensions of the tree that may overlap the objects in the tree, or just bb
+// if the tree has no periodic dimensions. If bb spans a whole period along a
+// dimension, its image covers the extent of the whole tree there instead.
+func (tree *Rtree) images(bb Rect) []Rect {
+	if tree.periods == nil || len(tree.root.entries) == 0 {
+		return []Rect{bb}
+	}
+	if len(bb.p) != len(tree.periods) {
+		panic(DimError{len(tree.periods), len(bb.p)})
+	}
+
+	extent := tree.root.computeBoundingBox()
+	images := []Rect{bb}
+	for i, period := range tree.periods {
+		if period <= 0 {
+			continue
+		}
+
+		var next []Rect
+		for _, r := range images {
+			if r.q[i]-r.p[i] >= period {
+				img := r.copy()
+				img.p[i] = math.Min(r.p[i], extent.p[i])
+				img.q[i] = math.Max(r.q[i], extent.q[i])
+				next = append(next, img)
+				continue
+			}
+
+			// shift by the multiples of the period that move r into the
+			// extent of the tree
+			lo := math.Ceil((extent.p[i] - r.q[i]) / period)
+			hi := math.Floor((extent.q[i] - r.p[i]) / period)
+			for k := lo; k <= hi; k++ {
+				img := r.copy()
+				img.p[i] += k * period
+				img.q[i] += k * period
+				next = append(next, img)
+			}
+		}
+		images = next
+	}
+	return images
+}
+
+// searchImages searches for objects satisfying match with any image of bb,
+// descending into the entries that satisfy descend with any image.
+func (tree *Rtree) searchImages(bb Rect, descend, match func(r, img Rect) bool, filters []Filter) []Spatial {
+	images := tree.images(bb)
+	anyImage := func(pred func(r, img Rect) bool) func(Rect) bool {
+		return func(r Rect) bool {
+			for _, img := range images {
+				if pred(r, img) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	results, _ := tree.search([]Spatial{}, tree.root, anyImage(descend), anyImage(match), filters)
+	return results
+}
+
+// closestImage returns the image of p shifted by whole periods along the
+// periodic dimensions of the tree which is closest to r. p is only copied if
+// it has to be shifted.
+func (tree *Rtree) closestImage(p Point, r Rect) Point {
+	if len(p) != len(tree.periods) {
+		panic(DimError{len(tree.periods), len(p)})
+	}
+
+	copied := false
+	for i, period := range tree.periods {
+		a, b := r.p[i], r.q[i]
+		if period <= 0 || (p[i] >= a && p[i] <= b) {
+			continue
+		}
+
+		// position of the closest image relative to the lower side of r
+		d := math.Mod(p[i]-a, period)
+		if d < 0 {
+			d += period
+		}
+		if d > b-a && period-d < d-(b-a) {
+			d -= period
+		}
+
+		if !copied {
+			p = p.Copy()
+			copied = true
+		}
+		p[i] = a + d
+	}
+	return p
+}
@@ -0,0 +1,135 @@
+package rtreego
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// periodicDist computes the Euclidean distance from p to the closest image of
+// r in a periodic box by trying the neighboring images in every dimension.
+func periodicDist(p Point, r Rect, periods []float64) float64 {
+	sum := 0.0
+	for i := range p {
+		gap := math.Inf(1)
+		for k := -1.0; k <= 1; k++ {
+			a, b := r.p[i]+k*periods[i], r.q[i]+k*periods[i]
+			gap = math.Min(gap, math.Max(0, math.Max(a-p[i], p[i]-b)))
+		}
+		sum += gap * gap
+	}
+	return math.Sqrt(sum)
+}
+
+func periodicParticles(n int, seed int64) []Spatial {
+	rnd := rand.New(rand.NewSource(seed))
+	things := make([]Spatial, n)
+	for i := range things {
+		r := mustRect(Point{rnd.Float64() * 9.9, rnd.Float64() * 9.9}, []float64{0.1, 0.1})
+		things[i] = &r
+	}
+	return things
+}
+
+func TestPeriodicNearestNeighbors(t *testing.T) {
+	periods := []float64{10, 10}
+	things := periodicParticles(200, 16)
+	rt := NewTreeWithOptions(2, 3, 6, Options{Periods: periods}, things...)
+
+	for _, p := range []Point{{0.1, 0.1}, {9.95, 5}, {5, 5}, {9.9, 9.9}} {
+		expected := append([]Spatial{}, things...)
+		sort.SliceStable(expected, func(i, j int) bool {
+			return periodicDist(p, expected[i].Bounds(), periods) < periodicDist(p, expected[j].Bounds(), periods)
+		})
+
+		objs, dists := rt.NearestNeighborsWithDistances(10, p)
+		for i := range objs {
+			if objs[i] != expected[i] {
+				t.Errorf("NearestNeighbors(%v) failed at index %d: %v != %v", p, i, objs[i], expected[i])
+			}
+			if d := periodicDist(p, expected[i].Bounds(), periods); math.Abs(dists[i]-d) > EPS {
+				t.Errorf("NearestNeighborsWithDistances(%v) distance at index %d: %v != %v", p, i, dists[i], d)
+			}
+		}
+
+		if obj := rt.NearestNeighbor(p); obj != expected[0] {
+			t.Errorf("NearestNeighbor(%v) = %v, expected %v", p, obj, expected[0])
+		}
+		if obj, _, _ := rt.NearestIterator(p).Next(); obj != expected[0] {
+			t.Errorf("NearestIterator(%v) = %v, expected %v", p, obj, expected[0])
+		}
+
+		radius := 1.5
+		var within []Spatial
+		for _, thing := range things {
+			if periodicDist(p, thing.Bounds(), periods) <= radius {
+				within = append(within, thing)
+			}
+		}
+		results := rt.SearchWithinDistance(p, radius)
+		if len(results) != len(within) {
+			t.Errorf("SearchWithinDistance(%v, %v) returned %d objects, expected %d", p, radius, len(results), len(within))
+		}
+		ensureDisorderedSubset(t, results, within)
+	}
+}
+
+func TestPeriodicSearchIntersect(t *testing.T) {
+	periods := []float64{10, 0}
+	corner := mustRect(Point{0, 0}, []float64{1, 1})
+	edge := mustRect(Point{9.5, 5}, []float64{0.4, 1})
+	middle := mustRect(Point{5, 5}, []float64{1, 1})
+	things := []Spatial{&corner, &edge, &middle}
+	rt := NewTreeWithOptions(2, 2, 3, Options{Periods: periods}, things...)
+
+	// wraps around in the first dimension only
+	results := rt.SearchIntersect(mustRect(Point{9, -0.5}, []float64{1.5, 1}))
+	if len(results) != 1 || results[0] != &corner {
+		t.Errorf("expected SearchIntersect to wrap around to %v, got %v", &corner, results)
+	}
+	results = rt.SearchIntersect(mustRect(Point{-1, 4}, []float64{2, 0.5}))
+	if len(results) != 0 {
+		t.Errorf("expected SearchIntersect not to wrap around in the second dimension, got %v", results)
+	}
+	results = rt.SearchIntersect(mustRect(Point{-1, 5}, []float64{2, 0.5}))
+	if len(results) != 1 || results[0] != &edge {
+		t.Errorf("expected SearchIntersect to wrap around to %v, got %v", &edge, results)
+	}
+
+	// a query spanning the whole period finds everything in its range
+	results = rt.SearchIntersect(mustRect(Point{3, 0}, []float64{10, 10}))
+	if len(results) != len(things) {
+		t.Errorf("expected SearchIntersect to find %d objects, got %v", len(things), results)
+	}
+}
+
+func TestClosestImage(t *testing.T) {
+	rt := NewTreeWithOptions(2, 2, 3, Options{Periods: []float64{10, 0}})
+	r := mustRect(Point{1, 1}, []float64{2, 2})
+	tests := []struct {
+		p, expected Point
+	}{
+		{Point{2, 2}, Point{2, 2}},
+		{Point{9, 2}, Point{-1, 2}},
+		{Point{5, 9}, Point{5, 9}},
+		{Point{6.9, 2}, Point{6.9, 2}},
+		{Point{7.1, 2}, Point{-2.9, 2}},
+		{Point{-25, 2}, Point{5, 2}},
+		{Point{32, 2}, Point{2, 2}},
+	}
+	for _, test := range tests {
+		if img := rt.closestImage(test.p, r); math.Abs(img[0]-test.expected[0]) > EPS || img[1] != test.expected[1] {
+			t.Errorf("expected closestImage(%v, %v) == %v, got %v", test.p, r, test.expected, img)
+		}
+	}
+}
+
+func TestPeriodsDimError(t *testing.T) {
+	defer func() {
+		if _, ok := recover().(DimError); !ok {
+			t.Errorf("expected DimError for mismatched periods")
+		}
+	}()
+	NewTreeWithOptions(2, 2, 3, Options{Periods: []float64{10}})
+}
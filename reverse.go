@@ -0,0 +1,127 @@
+// Copyright 2012 Daniel Connelly.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtreego
+
+import (
+	"container/heap"
+)
+
+// ReverseNearestNeighbors returns the objects which would have p among their
+// k nearest neighbors, that is, the objects with fewer than k other objects
+// closer to them than p. Every object is located at the center of its
+// bounding box, from where the distances to p and to the bounding boxes of
+// the other objects are measured as in NearestNeighbors. The objects are
+// returned in increasing order of their distance from p.
+//
+// In trees using the default Euclidean metric without periodic boundaries,
+// the candidates are found by traversing the tree in increasing order of
+// distance from p, pruning the subtrees which lie on the far side of the
+// perpendicular bisectors between p and k candidates found before. Each
+// candidate is then verified by counting the objects closer to it than p.
+//
+// Implemented per "Reverse kNN Search in Arbitrary Dimensionality" by Y. Tao,
+// D. Papadias and X. Lian, VLDB, p. 744-755, 2004.
+func (tree *Rtree) ReverseNearestNeighbors(k int, p Point) []Spatial {
+	results := []Spatial{}
+	if k <= 0 || tree.size == 0 {
+		return results
+	}
+
+	for _, c := range tree.reverseNearestCandidates(k, p) {
+		center := c.bb.center()
+		key := tree.minDist(p, Rect{p: center, q: center})
+
+		// the object itself is counted unless it is located at p
+		limit := k
+		if key > 0 {
+			limit++
+		}
+		if tree.countCloser(tree.root, center, key, limit) < limit {
+			results = append(results, c.obj)
+		}
+	}
+	return results
+}
+
+// reverseNearestCandidates returns the leaf entries of the objects which may
+// have p among their k nearest neighbors, in increasing order of distance
+// from p.
+func (tree *Rtree) reverseNearestCandidates(k int, p Point) []entry {
+	prune := tree.metric == nil && tree.periods == nil
+
+	var candidates []entry
+	queue := nearestQueue{}
+	heap.Push(&queue, nearestItem{entry: entry{bb: tree.root.computeBoundingBox(), child: tree.root}})
+	for queue.Len() > 0 {
+		item := heap.Pop(&queue).(nearestItem)
+		if item.child == nil {
+			center := item.bb.center()
+			if !prune || !bisected(Rect{p: center, q: center}, p, candidates, k) {
+				candidates = append(candidates, item.entry)
+			}
+			continue
+		}
+
+		if prune && bisected(item.bb, p, candidates, k) {
+			continue
+		}
+		for _, e := range item.child.entries {
+			heap.Push(&queue, nearestItem{entry: e, dist: tree.minDist(p, e.bb)})
+		}
+	}
+	return candidates
+}
+
+// bisected reports whether every object located in r is closer to at least k
+// of the candidates than to p, because r lies entirely on their side of the
+// perpendicular bisectors between them and p. Neither r nor its objects may
+// contain any of the candidates.
+func bisected(r Rect, p Point, candidates []entry, k int) bool {
+	count := 0
+	for _, c := range candidates {
+		// the points x closer to c than to p satisfy the linear inequality
+		// 2x·(p-c) < |p|²-|c|², which holds in all of r if it holds in the
+		// corner of r maximizing the left-hand side
+		center := c.bb.center()
+		lhs, rhs := 0.0, 0.0
+		for i := range p {
+			w := 2 * (p[i] - center[i])
+			if w > 0 {
+				lhs += w * r.q[i]
+			} else {
+				lhs += w * r.p[i]
+			}
+			rhs += p[i]*p[i] - center[i]*center[i]
+		}
+		if lhs < rhs {
+			count++
+			if count >= k {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// countCloser counts the objects in the subtree of n whose bounding boxes are
+// closer to c than the distance with the given key, as returned by minDist,
+// stopping as soon as the count reaches limit.
+func (tree *Rtree) countCloser(n *node, c Point, key float64, limit int) int {
+	count := 0
+	for _, e := range n.entries {
+		if tree.minDist(c, e.bb) >= key {
+			continue
+		}
+		if n.leaf {
+			count++
+		} else {
+			count += tree.countCloser(e.child, c, key, limit-count)
+		}
+		if count >= limit {
+			break
+		}
+	}
+	return count
+}
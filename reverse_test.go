@@ -0,0 +1,102 @@
+package rtreego
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// bruteForceReverseNearest returns the objects with fewer than k other
+// objects closer to the centers of their bounding boxes than p.
+func bruteForceReverseNearest(k int, p Point, things []Spatial) []Spatial {
+	var results []Spatial
+	for i, thing := range things {
+		c := thing.Bounds().center()
+		closer := 0
+		for j, other := range things {
+			if i != j && p.minDist(Rect{c, c}) > c.minDist(other.Bounds()) {
+				closer++
+			}
+		}
+		if closer < k {
+			results = append(results, thing)
+		}
+	}
+	return results
+}
+
+func TestReverseNearestNeighbors(t *testing.T) {
+	things := randomRects(300, 29)
+	for _, tc := range tests(2, 3, 6, things...) {
+		for _, k := range []int{1, 3, 10} {
+			t.Run(fmt.Sprintf("%s-%d", tc.name, k), func(t *testing.T) {
+				rt := tc.build()
+				rnd := rand.New(rand.NewSource(int64(k)))
+				for i := 0; i < 10; i++ {
+					p := Point{rnd.Float64() * 100, rnd.Float64() * 100}
+					expected := bruteForceReverseNearest(k, p, things)
+					results := rt.ReverseNearestNeighbors(k, p)
+					if len(results) != len(expected) {
+						t.Errorf("ReverseNearestNeighbors(%d, %v) returned %d objects, expected %d", k, p, len(results), len(expected))
+					}
+					ensureDisorderedSubset(t, results, expected)
+				}
+			})
+		}
+	}
+}
+
+func TestReverseNearestNeighborsMetric(t *testing.T) {
+	things := randomRects(100, 30)
+	rt := NewTreeWithOptions(2, 3, 6, Options{Metric: Manhattan{}}, things...)
+	p := Point{50, 50}
+
+	var expected []Spatial
+	for i, thing := range things {
+		c := thing.Bounds().center()
+		closer := 0
+		for j, other := range things {
+			if i != j && (Manhattan{}).MinDist(c, other.Bounds()) < (Manhattan{}).Dist(c, p) {
+				closer++
+			}
+		}
+		if closer < 2 {
+			expected = append(expected, thing)
+		}
+	}
+
+	results := rt.ReverseNearestNeighbors(2, p)
+	if len(results) != len(expected) {
+		t.Errorf("ReverseNearestNeighbors returned %d objects, expected %d", len(results), len(expected))
+	}
+	ensureDisorderedSubset(t, results, expected)
+}
+
+func TestReverseNearestNeighborsSimple(t *testing.T) {
+	// a row of points, where the new point is placed between the second
+	// and the third
+	var things []Spatial
+	for _, x := range []float64{0, 1, 5, 6, 20} {
+		r := Point{x, 0}.ToRect(0)
+		things = append(things, &r)
+	}
+	rt := NewTree(2, 2, 3, things...)
+
+	results := rt.ReverseNearestNeighbors(1, Point{3.2, 0})
+	if len(results) != 0 {
+		t.Errorf("expected no reverse nearest neighbor, got %v", results)
+	}
+	results = rt.ReverseNearestNeighbors(2, Point{3.2, 0})
+	expected := []Spatial{things[2], things[1], things[3], things[0]}
+	if len(results) != len(expected) {
+		t.Errorf("expected %d reverse nearest neighbors, got %v", len(expected), results)
+	}
+	ensureOrderedSubset(t, results, expected)
+	results = rt.ReverseNearestNeighbors(1, Point{15, 0})
+	if len(results) != 1 || results[0] != things[4] {
+		t.Errorf("expected %v as the reverse nearest neighbor, got %v", things[4], results)
+	}
+	if results := NewTree(2, 2, 3).ReverseNearestNeighbors(1, Point{0, 0}); len(results) != 0 {
+		t.Errorf("expected no results in an empty tree, got %v", results)
+	}
+}
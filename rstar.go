@@ -0,0 +1,226 @@
+// Copyright 2012 Daniel Connelly.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtreego
+
+import (
+	"math"
+	"sort"
+)
+
+// reinsertFraction is the share of a node's entries that are removed and
+// reinserted on the first overflow of each level, as recommended by
+// Beckmann et al.
+const reinsertFraction = 0.3
+
+// insertRStar adds e to the tree at the specified level using the R*-tree
+// algorithm. reinserted records the levels at which forced reinsertion has
+// already been done during the current top-level insertion.
+//
+// Implemented per Section 4 of "The R*-tree: An Efficient and Robust Access
+// Method for Points and Rectangles" by N. Beckmann, H.-P. Kriegel,
+// R. Schneider and B. Seeger, ACM SIGMOD, p. 322-331, 1990.
+func (tree *Rtree) insertRStar(e entry, level int, reinserted map[int]bool) {
+	n := tree.own(tree.chooseNode(tree.root, e, level))
+	n.entries = append(n.entries, e)
+
+	// update parent pointer if necessary
+	if e.child != nil {
+		e.child.parent = n
+	}
+
+	tree.overflowTreatment(n, reinserted)
+}
+
+// overflowTreatment resolves overflows from n upwards, either by forced
+// reinsertion or by splitting, and adjusts the bounding boxes on the way.
+func (tree *Rtree) overflowTreatment(n *node, reinserted map[int]bool) {
+	for {
+		if len(n.entries) <= tree.MaxChildren {
+			if n == tree.root {
+				return
+			}
+			en := n.getEntry()
+			prevBox := en.bb
+			en.bb = n.computeBoundingBox()
+			if en.bb.Equal(prevBox) {
+				// Nothing changed above this point.
+				return
+			}
+			n = n.parent
+			continue
+		}
+
+		// The first overflow at each level (except the root) is treated by
+		// reinserting some of the entries instead of splitting.
+		if n != tree.root && !reinserted[n.level] && tree.reinsertCount(n) > 0 {
+			reinserted[n.level] = true
+			tree.reinsert(n, reinserted)
+			return
+		}
+
+		left, right := tree.splitNode(n)
+		if left == tree.root {
+			tree.growRoot(left, right)
+			return
+		}
+		left.getEntry().bb = left.computeBoundingBox()
+		left.parent.entries = append(left.parent.entries, entry{bb: right.computeBoundingBox(), child: right})
+		n = left.parent
+	}
+}
+
+// reinsertCount returns the number of entries of the overflowing node n that
+// should be reinserted, making sure n does not underflow.
+func (tree *Rtree) reinsertCount(n *node) int {
+	p := int(math.Round(reinsertFraction * float64(tree.MaxChildren)))
+	if max := len(n.entries) - tree.MinChildren; p > max {
+		p = max
+	}
+	return p
+}
+
+// reinsert removes the entries of n whose centers are farthest away from the
+// center of n and inserts them again, starting with the closest one.
+func (tree *Rtree) reinsert(n *node, reinserted map[int]bool) {
+	center := n.computeBoundingBox().center()
+	dists := make([]float64, len(n.entries))
+	for i, e := range n.entries {
+		dists[i] = center.dist(e.bb.center())
+	}
+	sort.Sort(entrySlice{n.entries, dists})
+
+	keep := len(n.entries) - tree.reinsertCount(n)
+	removed := make([]entry, len(n.entries)-keep)
+	copy(removed, n.entries[keep:])
+	n.entries = n.entries[:keep]
+
+	// shrink the bounding boxes along the path to the root
+	for p := n; p != tree.root; p = p.parent {
+		p.getEntry().bb = p.computeBoundingBox()
+	}
+
+	for _, e := range removed {
+		tree.insertRStar(e, n.level, reinserted)
+	}
+}
+
+// leastOverlapEnlargement returns the index of the entry whose overlap with
+// its siblings grows least when enlarged to include bb. Ties are resolved by
+// least area enlargement and then by smallest area.
+func leastOverlapEnlargement(entries []entry, bb Rect) int {
+	chosen := -1
+	var minOverlap, minEnlargement, minSize float64
+	for i, en := range entries {
+		enlarged := boundingBox(en.bb, bb)
+		overlap := 0.0
+		for j, other := range entries {
+			if i != j {
+				overlap += overlapSize(enlarged, other.bb) - overlapSize(en.bb, other.bb)
+			}
+		}
+		size := en.bb.Size()
+		enlargement := enlarged.Size() - size
+
+		if chosen < 0 || overlap < minOverlap ||
+			(overlap == minOverlap && (enlargement < minEnlargement ||
+				(enlargement == minEnlargement && size < minSize))) {
+			chosen = i
+			minOverlap, minEnlargement, minSize = overlap, enlargement, size
+		}
+	}
+	return chosen
+}
+
+// splitRStar splits a node into two groups using the R*-tree topological
+// split. The split axis is the one minimizing the total margin of all
+// candidate distributions, and along that axis the distribution with the
+// least overlap (then the least area) is chosen.
+func (n *node) splitRStar(minGroupSize int) (left, right *node) {
+	entries := n.entries
+	count := len(entries)
+	m := clampGroupSize(minGroupSize, count)
+
+	// ChooseSplitAxis
+	dim := len(entries[0].bb.p)
+	bestAxis, minMargin := 0, math.MaxFloat64
+	for axis := 0; axis < dim; axis++ {
+		margin := 0.0
+		for _, upper := range []bool{false, true} {
+			sortByBound(entries, axis, upper)
+			lo, hi := groupBoundingBoxes(entries)
+			for k := m; k <= count-m; k++ {
+				margin += lo[k-1].margin() + hi[k].margin()
+			}
+		}
+		if margin < minMargin {
+			bestAxis, minMargin = axis, margin
+		}
+	}
+
+	// ChooseSplitIndex
+	bestUpper, bestK := false, m
+	minOverlap, minSize := math.MaxFloat64, math.MaxFloat64
+	for _, upper := range []bool{false, true} {
+		sortByBound(entries, bestAxis, upper)
+		lo, hi := groupBoundingBoxes(entries)
+		for k := m; k <= count-m; k++ {
+			overlap := overlapSize(lo[k-1], hi[k])
+			size := lo[k-1].Size() + hi[k].Size()
+			if overlap < minOverlap || (overlap == minOverlap && size < minSize) {
+				bestUpper, bestK = upper, k
+				minOverlap, minSize = overlap, size
+			}
+		}
+	}
+	sortByBound(entries, bestAxis, bestUpper)
+
+	// setup the new split nodes, but re-use n as the left node
+	left = n
+	left.entries = entries[:bestK]
+	right = &node{
+		parent: n.parent,
+		leaf:   n.leaf,
+		level:  n.level,
+		gen:    n.gen,
+	}
+	for _, e := range entries[bestK:] {
+		assign(e, right)
+	}
+	return
+}
+
+// sortByBound sorts entries by their lower or upper bound in the given
+// dimension, using the other bound to break ties.
+func sortByBound(entries []entry, dim int, upper bool) {
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i].bb, entries[j].bb
+		if upper {
+			if a.q[dim] != b.q[dim] {
+				return a.q[dim] < b.q[dim]
+			}
+			return a.p[dim] < b.p[dim]
+		}
+		if a.p[dim] != b.p[dim] {
+			return a.p[dim] < b.p[dim]
+		}
+		return a.q[dim] < b.q[dim]
+	})
+}
+
+// groupBoundingBoxes computes the bounding boxes of all prefixes and suffixes
+// of entries: lo[i] bounds entries[:i+1] and hi[i] bounds entries[i:].
+func groupBoundingBoxes(entries []entry) (lo, hi []Rect) {
+	lo = make([]Rect, len(entries))
+	hi = make([]Rect, len(entries))
+	lo[0] = entries[0].bb
+	for i := 1; i < len(entries); i++ {
+		lo[i] = boundingBox(lo[i-1], entries[i].bb)
+	}
+	hi[len(entries)-1] = entries[len(entries)-1].bb
+	for i := len(entries) - 2; i >= 0; i-- {
+		hi[i] = boundingBox(hi[i+1], entries[i].bb)
+	}
+	return
+}
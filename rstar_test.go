@@ -0,0 +1,160 @@
+package rtreego
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func randomRects(n int, seed int64) []Spatial {
+	r := rand.New(rand.NewSource(seed))
+	things := make([]Spatial, n)
+	for i := range things {
+		p := Point{r.Float64() * 100, r.Float64() * 100}
+		rect := mustRect(p, []float64{r.Float64() + 0.1, r.Float64() + 0.1})
+		things[i] = &rect
+	}
+	return things
+}
+
+func TestRStarInsertValidity(t *testing.T) {
+	things := randomRects(500, 1)
+	rt := NewTreeWithOptions(2, 3, 8, Options{InsertMode: RStarInsert})
+	for _, thing := range things {
+		rt.Insert(thing)
+		verify(t, rt)
+	}
+
+	if rt.Size() != len(things) {
+		t.Errorf("Insert failed to insert, got size %d", rt.Size())
+	}
+	for _, thing := range things {
+		if !contains(thing, rt.SearchIntersect(thing.Bounds())) {
+			t.Fatalf("SearchIntersect failed to find %v", thing)
+		}
+	}
+
+	for i, thing := range things {
+		if !rt.Delete(thing) {
+			t.Fatalf("Delete failed to delete %v", thing)
+		}
+		if i%50 == 0 {
+			verify(t, rt)
+		}
+	}
+	if rt.Size() != 0 {
+		t.Errorf("Delete failed to delete, got size %d", rt.Size())
+	}
+}
+
+func TestRStarChooseNodeLeastOverlap(t *testing.T) {
+	rt := NewTreeWithOptions(2, 1, 3, Options{InsertMode: RStarInsert})
+	rt.root = &node{level: 2}
+
+	// Both leaves need the same area enlargement, but enlarging the first
+	// one would make it overlap the third one.
+	bbs := []Rect{
+		mustRect(Point{0, 0}, []float64{1, 1}),
+		mustRect(Point{0, 3}, []float64{1, 1}),
+		mustRect(Point{0.5, 1.1}, []float64{1, 0.3}),
+	}
+	for _, bb := range bbs {
+		leaf := &node{parent: rt.root, level: 1, leaf: true}
+		rt.root.entries = append(rt.root.entries, entry{bb: bb, child: leaf})
+	}
+
+	obj := mustRect(Point{0, 1.5}, []float64{1, 1})
+	e := entry{obj, nil, obj}
+	if leaf := rt.chooseNode(rt.root, e, 1); leaf != rt.root.entries[1].child {
+		t.Errorf("expected chooseNode to pick the leaf without overlap")
+	}
+
+	rt.insertMode = GuttmanInsert
+	if leaf := rt.chooseNode(rt.root, e, 1); leaf != rt.root.entries[0].child {
+		t.Errorf("expected chooseNode to pick the first leaf with least enlargement")
+	}
+}
+
+func TestSplitRStar(t *testing.T) {
+	entries := []entry{
+		{bb: mustRect(Point{0, 0}, []float64{1, 1})},
+		{bb: mustRect(Point{10, 0.5}, []float64{1, 1})},
+		{bb: mustRect(Point{0, 1}, []float64{1, 1})},
+		{bb: mustRect(Point{11, 1}, []float64{1, 1})},
+		{bb: mustRect(Point{1, 0.5}, []float64{1, 1})},
+	}
+	n := &node{entries: entries, leaf: true, level: 1}
+
+	l, r := n.splitRStar(2)
+	if len(l.entries)+len(r.entries) != 5 {
+		t.Fatalf("splitRStar lost entries")
+	}
+
+	expLeft := mustRect(Point{0, 0}, []float64{2, 2})
+	expRight := mustRect(Point{10, 0.5}, []float64{2, 1.5})
+	if lbb, rbb := l.computeBoundingBox(), r.computeBoundingBox(); !rectEq(lbb, expLeft) || !rectEq(rbb, expRight) {
+		t.Errorf("expected split into %v and %v, got %v and %v", expLeft, expRight, lbb, rbb)
+	}
+	if r.leaf != n.leaf || r.level != n.level {
+		t.Errorf("expected right node to keep leaf and level")
+	}
+}
+
+func TestSplitRStarUnderflow(t *testing.T) {
+	entries := []entry{
+		{bb: mustRect(Point{0, 0}, []float64{1, 1})},
+		{bb: mustRect(Point{0, 1}, []float64{1, 1})},
+		{bb: mustRect(Point{0, 2}, []float64{1, 1})},
+		{bb: mustRect(Point{0, 3}, []float64{1, 1})},
+		{bb: mustRect(Point{-50, -50}, []float64{1, 1})},
+	}
+	n := &node{entries: entries}
+
+	l, r := n.splitRStar(2)
+	if len(l.entries) < 2 || len(r.entries) < 2 {
+		t.Errorf("expected splitRStar to respect the minimum group size, got %d and %d", len(l.entries), len(r.entries))
+	}
+}
+
+func TestRStarReinsert(t *testing.T) {
+	rt := NewTreeWithOptions(2, 2, 10, Options{InsertMode: RStarInsert})
+	rt.root = &node{level: 2}
+	rt.height = 2
+
+	// the first leaf overflows and contains one entry far away from the
+	// others, which is closer to the second leaf
+	near := &node{parent: rt.root, level: 1, leaf: true}
+	for i := 0; i <= rt.MaxChildren; i++ {
+		obj := mustRect(Point{float64(i % 4), float64(i / 4)}, []float64{1, 1})
+		near.entries = append(near.entries, entry{obj, nil, obj})
+	}
+	far := mustRect(Point{50, 50}, []float64{1, 1})
+	near.entries[rt.MaxChildren] = entry{far, nil, &far}
+
+	other := &node{parent: rt.root, level: 1, leaf: true}
+	for i := 0; i < 2; i++ {
+		obj := mustRect(Point{60, 60 + float64(i)}, []float64{1, 1})
+		other.entries = append(other.entries, entry{obj, nil, obj})
+	}
+
+	rt.root.entries = []entry{
+		{bb: near.computeBoundingBox(), child: near},
+		{bb: other.computeBoundingBox(), child: other},
+	}
+	rt.size = len(near.entries) + len(other.entries)
+
+	rt.reinsert(near, map[int]bool{1: true})
+
+	if len(rt.root.entries) != 2 {
+		t.Fatalf("expected reinsert not to split, got %d root entries", len(rt.root.entries))
+	}
+	if len(near.entries) != rt.MaxChildren || len(other.entries) != 3 {
+		t.Errorf("expected reinsert to move one entry, got %d and %d entries", len(near.entries), len(other.entries))
+	}
+	if other.entries[2].obj != Spatial(&far) {
+		t.Errorf("expected the farthest entry to be moved to the other leaf")
+	}
+	if !rectEq(rt.root.entries[0].bb, near.computeBoundingBox()) {
+		t.Errorf("expected reinsert to shrink the bounding box")
+	}
+	verify(t, rt)
+}
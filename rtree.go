@@ -35,12 +35,131 @@ type Rtree struct {
 
 	// FloatingPointTolerance is the tolerance to guard against floating point rounding errors during minMaxDist calculations.
 	FloatingPointTolerance float64
+
+	insertMode      InsertMode
+	splitStrategy   SplitStrategy
+	bulkLoadMode    BulkLoadMode
+	bulkLoadWorkers int
+
+	// hilbert maps points onto the Hilbert curve. It is only set if
+	// Options.HilbertBounds is given, which HilbertInsert requires.
+	hilbert *hilbertCurve
+
+	// metric measures distances in queries. It is nil for the default
+	// Euclidean metric, which uses squared distances internally.
+	metric Metric
+
+	// periods holds the period of every dimension, or zero for dimensions
+	// that do not wrap around. It is nil if no dimension wraps around.
+	periods []float64
+
+	// distanceObjects is set once an object implementing DistanceSpatial
+	// has been stored. Queries on other trees skip the check for it.
+	distanceObjects bool
+
+	// gen is the generation of the nodes which the tree may modify in
+	// place. Nodes of other generations may be shared with snapshots.
+	gen uint64
+
+	// readOnly is set in the snapshots returned by Snapshot.
+	readOnly bool
+}
+
+// InsertMode selects the algorithm used to add objects to an Rtree.
+type InsertMode int
+
+const (
+	// GuttmanInsert is the insertion algorithm described in Guttman's
+	// original R-tree paper. It is the default.
+	GuttmanInsert InsertMode = iota
+
+	// RStarInsert uses the R*-tree insertion algorithm: overlap-minimizing
+	// subtree selection at the leaf level, forced reinsertion on the first
+	// overflow of each level and the margin-based topological split.
+	RStarInsert
+
+	// HilbertInsert turns the tree into a Hilbert R-tree: the entries of
+	// every node are kept ordered by the Hilbert value of their centers,
+	// which guides the choice of the leaf, and overflowing nodes share their
+	// entries with a sibling, splitting two nodes into three only when both
+	// are full. The split strategy is not used in this mode, and the tree is
+	// always bulk-loaded with HilbertBulkLoad. Options.HilbertBounds must be
+	// set, otherwise NewTreeWithOptions panics.
+	HilbertInsert
+)
+
+// Options configures an Rtree created by NewTreeWithOptions. The zero value
+// yields the same tree as NewTree.
+type Options struct {
+	// InsertMode selects the insertion algorithm.
+	InsertMode InsertMode
+
+	// SplitStrategy divides the entries of overflowing nodes. If nil,
+	// RStarSplit is used with RStarInsert and QuadraticSplit otherwise.
+	SplitStrategy SplitStrategy
+
+	// BulkLoadMode selects the algorithm used to bulk-load the objects
+	// given on initialization.
+	BulkLoadMode BulkLoadMode
+
+	// BulkLoadWorkers is the number of goroutines used by OMTBulkLoad and
+	// STRBulkLoad, which build the same tree regardless of their number.
+	// Values below two load on the calling goroutine only.
+	BulkLoadWorkers int
+
+	// HilbertBounds is the region mapped onto the Hilbert curve by
+	// HilbertInsert. Objects may lie outside of it, but their order along
+	// the curve becomes coarser. It is also used by HilbertBulkLoad if set;
+	// otherwise the bounding box of the loaded objects is used.
+	HilbertBounds Rect
+
+	// Metric measures distances in nearest-neighbor and radius queries. If
+	// nil, the Euclidean metric is used.
+	Metric Metric
+
+	// WrapLongitude declares that the first dimension holds longitudes in
+	// degrees, which wrap around at the antimeridian. Rectangles crossing
+	// the antimeridian, as constructed by NewGeoRect, are then found by
+	// SearchIntersect, SearchContained, SearchContaining and SearchPoint
+	// on both of its sides. It is a shorthand for a period of 360 in the
+	// first dimension.
+	WrapLongitude bool
+
+	// Periods declares periodic (toroidal) boundaries, giving the period of
+	// every dimension, or zero for dimensions without one. Queries then
+	// also find the objects near the images of the query shifted by whole
+	// periods, and distances are measured to the closest image. If set, it
+	// must have one entry per dimension.
+	Periods []float64
 }
 
+// BulkLoadMode selects the algorithm used to bulk-load an Rtree.
+type BulkLoadMode int
+
+const (
+	// OMTBulkLoad is the Overlap Minimizing Top-down bulk-loading algorithm.
+	// It is the default.
+	OMTBulkLoad BulkLoadMode = iota
+
+	// STRBulkLoad is the Sort-Tile-Recursive bulk-loading algorithm, which
+	// packs the leaves fully and works especially well for point-like data.
+	STRBulkLoad
+
+	// HilbertBulkLoad sorts the objects by the Hilbert value of their
+	// centers and packs them into the leaves in that order.
+	HilbertBulkLoad
+)
+
 // NewTree returns an Rtree. If the number of objects given on initialization
 // is larger than max, the Rtree will be initialized using the Overlap
 // Minimizing Top-down bulk-loading algorithm.
 func NewTree(dim, min, max int, objs ...Spatial) *Rtree {
+	return NewTreeWithOptions(dim, min, max, Options{}, objs...)
+}
+
+// NewTreeWithOptions is like NewTree, but allows configuring the tree with
+// the given options.
+func NewTreeWithOptions(dim, min, max int, opts Options, objs ...Spatial) *Rtree {
 	rt := &Rtree{
 		Dim:                    dim,
 		MinChildren:            min,
@@ -52,6 +171,26 @@ func NewTree(dim, min, max int, objs ...Spatial) *Rtree {
 			leaf:    true,
 			level:   1,
 		},
+		insertMode:      opts.InsertMode,
+		splitStrategy:   opts.SplitStrategy,
+		bulkLoadMode:    opts.BulkLoadMode,
+		bulkLoadWorkers: opts.BulkLoadWorkers,
+	}
+
+	if rt.insertMode == HilbertInsert && len(opts.HilbertBounds.p) == 0 {
+		panic(fmt.Errorf("rtreego: HilbertInsert requires Options.HilbertBounds"))
+	}
+	if len(opts.HilbertBounds.p) > 0 {
+		rt.hilbert = newHilbertCurve(dim, opts.HilbertBounds)
+	}
+	if rt.insertMode == HilbertInsert {
+		rt.bulkLoadMode = HilbertBulkLoad
+	}
+	if _, ok := opts.Metric.(Euclidean); !ok {
+		rt.metric = opts.Metric
+	}
+	if opts.Periods != nil || opts.WrapLongitude {
+		rt.periods = newPeriods(dim, opts.Periods, opts.WrapLongitude)
 	}
 
 	if len(objs) <= rt.MaxChildren {
@@ -59,7 +198,7 @@ func NewTree(dim, min, max int, objs ...Spatial) *Rtree {
 			rt.Insert(obj)
 		}
 	} else {
-		rt.bulkLoad(objs)
+		rt.load(objs)
 	}
 
 	return rt
@@ -111,19 +250,26 @@ func sortByDim(dim int, objs []entry) {
 	sort.Sort(&dimSorter{dim, objs})
 }
 
-// bulkLoad bulk loads the Rtree using OMT algorithm. bulkLoad contains special
-// handling for the root node.
-func (tree *Rtree) bulkLoad(objs []Spatial) {
-	n := len(objs)
-
-	// create entries for all the objects
-	entries := make([]entry, n)
+// objectEntries creates leaf entries for all the objects.
+func (tree *Rtree) objectEntries(objs []Spatial) []entry {
+	entries := make([]entry, len(objs))
 	for i := range objs {
 		entries[i] = entry{
 			bb:  objs[i].Bounds(),
 			obj: objs[i],
 		}
+		if _, ok := objs[i].(DistanceSpatial); ok {
+			tree.distanceObjects = true
+		}
 	}
+	return entries
+}
+
+// bulkLoad bulk loads the Rtree using OMT algorithm. bulkLoad contains special
+// handling for the root node.
+func (tree *Rtree) bulkLoad(objs []Spatial) {
+	n := len(objs)
+	entries := tree.objectEntries(objs)
 
 	// following equations are defined in the paper describing OMT
 	var (
@@ -145,24 +291,31 @@ func (tree *Rtree) bulkLoad(objs []Spatial) {
 	S := math.Floor(math.Sqrt(s))
 
 	// sort all entries by first dimension
-	sortByDim(0, entries)
+	pool := newWorkerPool(tree.bulkLoadWorkers)
+	keys := make([]float64, n)
+	for i, e := range entries {
+		keys[i] = e.bb.p[0]
+	}
+	sortByKeys(entries, keys, pool)
 
 	tree.height = int(h)
 	tree.size = n
-	tree.root = tree.omt(int(h), int(S), entries, int(s))
+	tree.root = tree.omt(int(h), int(S), entries, int(s), pool)
 }
 
 // omt is the recursive part of the Overlap Minimizing Top-loading bulk-
-// load approach. Returns the root node of a subtree.
-func (tree *Rtree) omt(level, nSlices int, objs []entry, m int) *node {
+// load approach. Returns the root node of a subtree. The sub trees are
+// created in parallel on the goroutines of pool.
+func (tree *Rtree) omt(level, nSlices int, objs []entry, m int, pool workerPool) *node {
 	// if number of objects is less than or equal than max children per leaf,
 	// we need to create a leaf node
 	if len(objs) <= m {
 		// as long as the recursion is not at the leaf, call it again
 		if level > 1 {
-			child := tree.omt(level-1, nSlices, objs, m)
+			child := tree.omt(level-1, nSlices, objs, m, pool)
 			n := &node{
 				level: level,
+				gen:   tree.gen,
 				entries: []entry{{
 					bb:    child.computeBoundingBox(),
 					child: child,
@@ -177,12 +330,14 @@ func (tree *Rtree) omt(level, nSlices int, objs []entry, m int) *node {
 			leaf:    true,
 			entries: entries,
 			level:   level,
+			gen:     tree.gen,
 		}
 	}
 
 	n := &node{
 		level:   level,
 		entries: make([]entry, 0, m),
+		gen:     tree.gen,
 	}
 
 	// maximum node size given at most M nodes at this level
@@ -195,22 +350,37 @@ func (tree *Rtree) omt(level, nSlices int, objs []entry, m int) *node {
 		vertSize = nSlices * k
 	}
 
-	// create sub trees
+	// sort the vertical slices, which do not overlap, in parallel by a
+	// different dimension on every level
+	var verts [][]entry
 	walkPartitions(vertSize, objs, func(vert []entry) {
-		// sort vertical slice by a different dimension on every level
-		sortByDim((tree.height-level+1)%tree.Dim, vert)
+		verts = append(verts, vert)
+	})
+	pool.each(len(verts), func(i int) {
+		sortByDim((tree.height-level+1)%tree.Dim, verts[i])
+	})
 
-		// split slice into groups of size k
+	// split slices into groups of size k
+	var parts [][]entry
+	for _, vert := range verts {
 		walkPartitions(k, vert, func(part []entry) {
-			child := tree.omt(level-1, 1, part, tree.MaxChildren)
-			child.parent = n
-
-			n.entries = append(n.entries, entry{
-				bb:    child.computeBoundingBox(),
-				child: child,
-			})
+			parts = append(parts, part)
 		})
+	}
+
+	// create sub trees, which only reorder their own groups
+	children := make([]*node, len(parts))
+	pool.each(len(parts), func(i int) {
+		children[i] = tree.omt(level-1, 1, parts[i], tree.MaxChildren, pool)
 	})
+
+	for _, child := range children {
+		child.parent = n
+		n.entries = append(n.entries, entry{
+			bb:    child.computeBoundingBox(),
+			child: child,
+		})
+	}
 	return n
 }
 
@@ -220,6 +390,7 @@ type node struct {
 	entries []entry
 	level   int // node depth in the Rtree
 	leaf    bool
+	gen     uint64 // generation of the tree which created the node
 }
 
 func (n *node) String() string {
@@ -245,6 +416,20 @@ type Spatial interface {
 	Bounds() Rect
 }
 
+// DistanceSpatial is an optional interface for Spatial objects that can compute
+// their exact distance to a point, such as line strings or polygons whose
+// bounding boxes are a poor approximation of their shape. Nearest-neighbor
+// queries rank such objects by DistanceTo instead of the distance to their
+// bounding boxes, which are still used to prune the search.
+//
+// DistanceTo must measure distances in the metric of the tree, and must never
+// be smaller than the distance from the point to the object's bounding box.
+// The bounding box should be the smallest one enclosing the object.
+type DistanceSpatial interface {
+	Spatial
+	DistanceTo(p Point) float64
+}
+
 // Insertion
 
 // Insert inserts a spatial object into the tree.  If insertion
@@ -253,6 +438,10 @@ type Spatial interface {
 // Implemented per Section 3.2 of "R-trees: A Dynamic Index Structure for
 // Spatial Searching" by A. Guttman, Proceedings of ACM SIGMOD, p. 47-57, 1984.
 func (tree *Rtree) Insert(obj Spatial) {
+	tree.checkWritable()
+	if _, ok := obj.(DistanceSpatial); ok {
+		tree.distanceObjects = true
+	}
 	e := entry{obj.Bounds(), nil, obj}
 	tree.insert(e, 1)
 	tree.size++
@@ -260,7 +449,16 @@ func (tree *Rtree) Insert(obj Spatial) {
 
 // insert adds the specified entry to the tree at the specified level.
 func (tree *Rtree) insert(e entry, level int) {
-	leaf := tree.chooseNode(tree.root, e, level)
+	switch tree.insertMode {
+	case RStarInsert:
+		tree.insertRStar(e, level, map[int]bool{})
+		return
+	case HilbertInsert:
+		tree.insertHilbert(e, level)
+		return
+	}
+
+	leaf := tree.own(tree.chooseNode(tree.root, e, level))
 	leaf.entries = append(leaf.entries, e)
 
 	// update parent pointer if necessary
@@ -271,23 +469,29 @@ func (tree *Rtree) insert(e entry, level int) {
 	// split leaf if overflows
 	var split *node
 	if len(leaf.entries) > tree.MaxChildren {
-		leaf, split = leaf.split(tree.MinChildren)
+		leaf, split = tree.splitNode(leaf)
 	}
 	root, splitRoot := tree.adjustTree(leaf, split)
 	if splitRoot != nil {
-		oldRoot := root
-		tree.height++
-		tree.root = &node{
-			parent: nil,
-			level:  tree.height,
-			entries: []entry{
-				{bb: oldRoot.computeBoundingBox(), child: oldRoot},
-				{bb: splitRoot.computeBoundingBox(), child: splitRoot},
-			},
-		}
-		oldRoot.parent = tree.root
-		splitRoot.parent = tree.root
+		tree.growRoot(root, splitRoot)
+	}
+}
+
+// growRoot replaces the root of the tree with a new node whose children are
+// the two halves of the split old root.
+func (tree *Rtree) growRoot(oldRoot, splitRoot *node) {
+	tree.height++
+	tree.root = &node{
+		parent: nil,
+		level:  tree.height,
+		gen:    tree.gen,
+		entries: []entry{
+			{bb: oldRoot.computeBoundingBox(), child: oldRoot},
+			{bb: splitRoot.computeBoundingBox(), child: splitRoot},
+		},
 	}
+	oldRoot.parent = tree.root
+	splitRoot.parent = tree.root
 }
 
 // chooseNode finds the node at the specified level to which e should be added.
@@ -296,21 +500,33 @@ func (tree *Rtree) chooseNode(n *node, e entry, level int) *node {
 		return n
 	}
 
-	// find the entry whose bb needs least enlargement to include obj
-	diff := math.MaxFloat64
+	// The R*-tree minimizes overlap when choosing among leaves, and falls
+	// back to the original least-enlargement criterion everywhere else.
 	var chosen entry
-	for _, en := range n.entries {
-		bb := boundingBox(en.bb, e.bb)
-		d := bb.Size() - en.bb.Size()
-		if d < diff || (d == diff && en.bb.Size() < chosen.bb.Size()) {
-			diff = d
-			chosen = en
-		}
+	if tree.insertMode == RStarInsert && n.level == 2 {
+		chosen = n.entries[leastOverlapEnlargement(n.entries, e.bb)]
+	} else {
+		chosen = n.entries[leastEnlargement(n.entries, e.bb)]
 	}
 
 	return tree.chooseNode(chosen.child, e, level)
 }
 
+// leastEnlargement returns the index of the entry whose bb needs least
+// enlargement to include bb, resolving ties by choosing the smallest entry.
+func leastEnlargement(entries []entry, bb Rect) int {
+	diff := math.MaxFloat64
+	chosen := 0
+	for i, en := range entries {
+		d := boundingBox(en.bb, bb).Size() - en.bb.Size()
+		if d < diff || (d == diff && en.bb.Size() < entries[chosen].bb.Size()) {
+			diff = d
+			chosen = i
+		}
+	}
+	return chosen
+}
+
 // adjustTree splits overflowing nodes and propagates the changes upwards.
 func (tree *Rtree) adjustTree(n, nn *node) (*node, *node) {
 	// Let the caller handle root adjustments.
@@ -340,7 +556,7 @@ func (tree *Rtree) adjustTree(n, nn *node) (*node, *node) {
 
 	// If the new entry overflows the parent, split the parent and propagate.
 	if len(n.parent.entries) > tree.MaxChildren {
-		return tree.adjustTree(n.parent.split(tree.MinChildren))
+		return tree.adjustTree(tree.splitNode(n.parent))
 	}
 
 	// Otherwise keep propagating changes upwards.
@@ -392,6 +608,7 @@ func (n *node) split(minGroupSize int) (left, right *node) {
 		leaf:    n.leaf,
 		level:   n.level,
 		entries: []entry{rightSeed},
+		gen:     n.gen,
 	}
 
 	// TODO
@@ -529,6 +746,7 @@ func (tree *Rtree) Delete(obj Spatial) bool {
 // an object from a tree but don't have a pointer to the original object
 // anymore.
 func (tree *Rtree) DeleteWithComparator(obj Spatial, cmp Comparator) bool {
+	tree.checkWritable()
 	n := tree.findLeaf(tree.root, obj, cmp)
 	if n == nil {
 		return false
@@ -544,6 +762,7 @@ func (tree *Rtree) DeleteWithComparator(obj Spatial, cmp Comparator) bool {
 		return false
 	}
 
+	n = tree.own(n)
 	n.entries = append(n.entries[:ind], n.entries[ind+1:]...)
 
 	tree.condenseTree(n)
@@ -619,7 +838,8 @@ func (tree *Rtree) condenseTree(n *node) {
 
 	for n != tree.root {
 		if len(n.entries) < tree.MinChildren {
-			// find n and delete it by swapping the last entry into its place
+			// find n and delete it by swapping the last entry into its place,
+			// unless the entries have to stay in Hilbert order
 			idx := -1
 			for i, e := range n.parent.entries {
 				if e.child == n {
@@ -631,7 +851,11 @@ func (tree *Rtree) condenseTree(n *node) {
 				panic(fmt.Errorf("Failed to remove entry from parent"))
 			}
 			l := len(n.parent.entries)
-			n.parent.entries[idx] = n.parent.entries[l-1]
+			if tree.insertMode == HilbertInsert {
+				copy(n.parent.entries[idx:], n.parent.entries[idx+1:])
+			} else {
+				n.parent.entries[idx] = n.parent.entries[l-1]
+			}
 			n.parent.entries = n.parent.entries[:l-1]
 
 			// only add n to deleted if it still has children
@@ -667,6 +891,9 @@ func (tree *Rtree) condenseTree(n *node) {
 // Implemented per Section 3.1 of "R-trees: A Dynamic Index Structure for
 // Spatial Searching" by A. Guttman, Proceedings of ACM SIGMOD, p. 47-57, 1984.
 func (tree *Rtree) SearchIntersect(bb Rect, filters ...Filter) []Spatial {
+	if tree.periods != nil {
+		return tree.searchImages(bb, intersect, intersect, filters)
+	}
 	return tree.searchIntersect([]Spatial{}, tree.root, bb, filters)
 }
 
@@ -711,6 +938,16 @@ func (tree *Rtree) searchIntersect(results []Spatial, n *node, bb Rect, filters
 // NearestNeighbor returns the closest object to the specified point.
 // Implemented per "Nearest Neighbor Queries" by Roussopoulos et al
 func (tree *Rtree) NearestNeighbor(p Point) Spatial {
+	if tree.metric != nil || tree.periods != nil || tree.distanceObjects {
+		// the minMaxDist pruning only holds for the Euclidean distances to
+		// the bounding boxes. p is copied so that it does not escape to the
+		// heap on the default path.
+		objs, _ := tree.findNearestNeighbors(1, p.Copy(), nil)
+		if len(objs) == 0 {
+			return nil
+		}
+		return objs[0]
+	}
 	obj, _ := tree.nearestNeighbor(p, tree.root, math.MaxFloat64, nil)
 	return obj
 }
@@ -746,17 +983,17 @@ func (s entrySlice) Less(i, j int) bool {
 func sortEntries(p Point, entries []entry) ([]entry, []float64) {
 	sorted := make([]entry, len(entries))
 	dists := make([]float64, len(entries))
-	return sortPreallocEntries(p, entries, sorted, dists)
+	return sortPreallocEntries(p.minDist, entries, sorted, dists)
 }
 
-func sortPreallocEntries(p Point, entries, sorted []entry, dists []float64) ([]entry, []float64) {
+func sortPreallocEntries(minDist func(Rect) float64, entries, sorted []entry, dists []float64) ([]entry, []float64) {
 	// use preallocated slices
 	sorted = sorted[:len(entries)]
 	dists = dists[:len(entries)]
 
 	for i := 0; i < len(entries); i++ {
 		sorted[i] = entries[i]
-		dists[i] = p.minDist(entries[i].bb)
+		dists[i] = minDist(entries[i].bb)
 	}
 	sort.Sort(entrySlice{sorted, dists})
 	return sorted, dists
@@ -816,20 +1053,64 @@ func (tree *Rtree) nearestNeighbor(p Point, n *node, d float64, nearest Spatial)
 	return nearest, d
 }
 
-// NearestNeighbors gets the closest Spatials to the Point.
+// NearestNeighbors gets the closest Spatials to the Point, measuring
+// distances with the tree's metric.
 func (tree *Rtree) NearestNeighbors(k int, p Point, filters ...Filter) []Spatial {
+	objs, _ := tree.findNearestNeighbors(k, p, filters)
+	return objs
+}
+
+// NearestNeighborsWithDistances gets the closest Spatials to the Point along
+// with their distances from the Point, in increasing order. The distance to an
+// object is the distance to the closest point of its bounding box, unless it
+// implements DistanceSpatial.
+func (tree *Rtree) NearestNeighborsWithDistances(k int, p Point, filters ...Filter) ([]Spatial, []float64) {
+	objs, dists := tree.findNearestNeighbors(k, p, filters)
+	for i, d := range dists {
+		dists[i] = tree.keyDist(d)
+	}
+	return objs, dists
+}
+
+// findNearestNeighbors returns the k nearest neighbors of p and the keys of
+// their distances, as returned by minDist.
+func (tree *Rtree) findNearestNeighbors(k int, p Point, filters []Filter) ([]Spatial, []float64) {
 	// preallocate the buffers for sortings the branches. At each level of the
 	// tree, we slide the buffer by the number of entries in the node.
 	maxBufSize := tree.MaxChildren * tree.Depth()
 	branches := make([]entry, maxBufSize)
 	branchDists := make([]float64, maxBufSize)
+	return tree.nearestNeighborsBuffered(k, p, filters, branches, branchDists)
+}
 
+// nearestNeighborsBuffered is like findNearestNeighbors, but sorts the
+// branches in the given buffers, which must hold MaxChildren entries for
+// every level of the tree.
+func (tree *Rtree) nearestNeighborsBuffered(k int, p Point, filters []Filter, branches []entry, branchDists []float64) ([]Spatial, []float64) {
 	// allocate the buffers for the results
 	dists := make([]float64, 0, k)
 	objs := make([]Spatial, 0, k)
 
-	objs, _, _ = tree.nearestNeighbors(k, p, tree.root, dists, objs, filters, branches, branchDists)
-	return objs
+	// choose the distances to the nodes and to the objects once for the
+	// whole query
+	minDist := p.minDist
+	objectDist := func(e entry) float64 {
+		return p.minDist(e.bb)
+	}
+	if tree.metric != nil || tree.periods != nil || tree.distanceObjects {
+		// p is copied so that it does not escape to the heap on the
+		// default path.
+		q := p.Copy()
+		minDist = func(r Rect) float64 {
+			return tree.minDist(q, r)
+		}
+		objectDist = func(e entry) float64 {
+			return tree.objectDist(q, e)
+		}
+	}
+
+	objs, dists, _ = tree.nearestNeighbors(k, minDist, objectDist, tree.root, dists, objs, filters, branches, branchDists)
+	return objs, dists
 }
 
 // insert obj into nearest and return the first k elements in increasing order.
@@ -865,24 +1146,24 @@ func insertNearest(k int, dists []float64, nearest []Spatial, dist float64, obj
 	return dists, nearest, false
 }
 
-func (tree *Rtree) nearestNeighbors(k int, p Point, n *node, dists []float64, nearest []Spatial, filters []Filter, b []entry, bd []float64) ([]Spatial, []float64, bool) {
+func (tree *Rtree) nearestNeighbors(k int, minDist func(Rect) float64, objectDist func(entry) float64, n *node, dists []float64, nearest []Spatial, filters []Filter, b []entry, bd []float64) ([]Spatial, []float64, bool) {
 	var abort bool
 	if n.leaf {
 		for _, e := range n.entries {
-			dist := p.minDist(e.bb)
+			dist := objectDist(e)
 			dists, nearest, abort = insertNearest(k, dists, nearest, dist, e.obj, filters)
 			if abort {
 				break
 			}
 		}
 	} else {
-		branches, branchDists := sortPreallocEntries(p, n.entries, b, bd)
+		branches, branchDists := sortPreallocEntries(minDist, n.entries, b, bd)
 		// only prune if buffer has k elements
 		if l := len(dists); l >= k {
 			branches = pruneEntriesMinDist(dists[l-1], branches, branchDists)
 		}
 		for _, e := range branches {
-			nearest, dists, abort = tree.nearestNeighbors(k, p, e.child, dists, nearest, filters, b[len(n.entries):], bd[len(n.entries):])
+			nearest, dists, abort = tree.nearestNeighbors(k, minDist, objectDist, e.child, dists, nearest, filters, b[len(n.entries):], bd[len(n.entries):])
 			if abort {
 				break
 			}
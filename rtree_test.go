@@ -3,6 +3,7 @@ package rtreego
 import (
 	"fmt"
 	"log"
+	"math"
 	"math/rand"
 	"sort"
 	"strings"
@@ -32,6 +33,12 @@ func tests(dim, min, max int, objs ...Spatial) []*testCase {
 				return NewTree(dim, min, max, objs...)
 			},
 		},
+		{
+			"STR bulk-loaded",
+			func() *Rtree {
+				return NewTreeWithOptions(dim, min, max, Options{BulkLoadMode: STRBulkLoad}, objs...)
+			},
+		},
 	}
 }
 
@@ -205,13 +212,13 @@ func TestChooseLeafNode(t *testing.T) {
 		rt := Rtree{}
 		rt.root = &node{}
 
-		leaf0 := &node{rt.root, []entry{}, 1, true}
+		leaf0 := &node{parent: rt.root, entries: []entry{}, level: 1, leaf: true}
 		entry0 := entry{test.bb0, leaf0, nil}
 
-		leaf1 := &node{rt.root, []entry{}, 1, true}
+		leaf1 := &node{parent: rt.root, entries: []entry{}, level: 1, leaf: true}
 		entry1 := entry{test.bb1, leaf1, nil}
 
-		leaf2 := &node{rt.root, []entry{}, 1, true}
+		leaf2 := &node{parent: rt.root, entries: []entry{}, level: 1, leaf: true}
 		entry2 := entry{test.bb2, leaf2, nil}
 
 		rt.root.entries = []entry{entry0, entry1, entry2}
@@ -347,7 +354,7 @@ func TestAdjustTreeNoPreviousSplit(t *testing.T) {
 	r01 := entry{bb: mustRect(Point{0, 1}, []float64{1, 1})}
 	r10 := entry{bb: mustRect(Point{1, 0}, []float64{1, 1})}
 	entries := []entry{r00, r01, r10}
-	n := node{rt.root, entries, 1, false}
+	n := node{parent: rt.root, entries: entries, level: 1}
 	rt.root.entries = []entry{{bb: Point{0, 0}.ToRect(0), child: &n}}
 
 	rt.adjustTree(&n, nil)
@@ -364,12 +371,12 @@ func TestAdjustTreeNoSplit(t *testing.T) {
 
 	r00 := entry{bb: mustRect(Point{0, 0}, []float64{1, 1})}
 	r01 := entry{bb: mustRect(Point{0, 1}, []float64{1, 1})}
-	left := node{rt.root, []entry{r00, r01}, 1, false}
+	left := node{parent: rt.root, entries: []entry{r00, r01}, level: 1}
 	leftEntry := entry{bb: Point{0, 0}.ToRect(0), child: &left}
 
 	r10 := entry{bb: mustRect(Point{1, 0}, []float64{1, 1})}
 	r11 := entry{bb: mustRect(Point{1, 1}, []float64{1, 1})}
-	right := node{rt.root, []entry{r10, r11}, 1, false}
+	right := node{parent: rt.root, entries: []entry{r10, r11}, level: 1}
 
 	rt.root.entries = []entry{leftEntry}
 	retl, retr := rt.adjustTree(&left, &right)
@@ -397,12 +404,12 @@ func TestAdjustTreeSplitParent(t *testing.T) {
 
 	r00 := entry{bb: mustRect(Point{0, 0}, []float64{1, 1})}
 	r01 := entry{bb: mustRect(Point{0, 1}, []float64{1, 1})}
-	left := node{rt.root, []entry{r00, r01}, 1, false}
+	left := node{parent: rt.root, entries: []entry{r00, r01}, level: 1}
 	leftEntry := entry{bb: Point{0, 0}.ToRect(0), child: &left}
 
 	r10 := entry{bb: mustRect(Point{1, 0}, []float64{1, 1})}
 	r11 := entry{bb: mustRect(Point{1, 1}, []float64{1, 1})}
-	right := node{rt.root, []entry{r10, r11}, 1, false}
+	right := node{parent: rt.root, entries: []entry{r10, r11}, level: 1}
 
 	rt.root.entries = []entry{leftEntry}
 	retl, retr := rt.adjustTree(&left, &right)
@@ -1353,6 +1360,53 @@ func TestNearestNeighborsHalf(t *testing.T) {
 	}
 }
 
+func TestNearestNeighborsWithDistances(t *testing.T) {
+	rects := []Rect{
+		mustRect(Point{1, 1}, []float64{1, 1}),
+		mustRect(Point{-7, -7}, []float64{1, 1}),
+		mustRect(Point{1, 3}, []float64{1, 1}),
+		mustRect(Point{7, 7}, []float64{1, 1}),
+		mustRect(Point{10, 2}, []float64{1, 1}),
+		mustRect(Point{3, 3}, []float64{1, 1}),
+	}
+	things := []Spatial{}
+	for i := range rects {
+		things = append(things, &rects[i])
+	}
+
+	p := Point{0.5, 0.5}
+	sort.Sort(byMinDist{things, p})
+
+	for _, tc := range tests(2, 3, 3, things...) {
+		t.Run(tc.name, func(t *testing.T) {
+			rt := tc.build()
+
+			objs, dists := rt.NearestNeighborsWithDistances(4, p)
+			if len(objs) != 4 || len(dists) != 4 {
+				t.Fatalf("NearestNeighborsWithDistances returned %d objects and %d distances, expected 4", len(objs), len(dists))
+			}
+			for i := range objs {
+				if objs[i] != things[i] {
+					t.Errorf("NearestNeighborsWithDistances failed at index %d: %v != %v", i, objs[i], things[i])
+				}
+				if expected := math.Sqrt(p.minDist(things[i].Bounds())); math.Abs(dists[i]-expected) > EPS {
+					t.Errorf("NearestNeighborsWithDistances distance at index %d: %v != %v", i, dists[i], expected)
+				}
+			}
+
+			// the first object is at (1, 1), half a unit away on both axes
+			if math.Abs(dists[0]-math.Sqrt(0.5)) > EPS {
+				t.Errorf("expected distance %v, got %v", math.Sqrt(0.5), dists[0])
+			}
+
+			objs, dists = rt.NearestNeighborsWithDistances(len(things), p, LimitFilter(2))
+			if len(objs) != 2 || len(dists) != 2 {
+				t.Errorf("NearestNeighborsWithDistances with LimitFilter(2) returned %d objects and %d distances", len(objs), len(dists))
+			}
+		})
+	}
+}
+
 func TestMinMaxDistFloatingPointRoundingError(t *testing.T) {
 	rects := []Rect{
 		Point{1134900, 15600}.ToRect(0),
@@ -1436,3 +1490,37 @@ func contains(obj Spatial, slice []Spatial) bool {
 
 	return false
 }
+
+// benchmarkTree returns a large tree of random rectangles and random query
+// points within it.
+func benchmarkTree() (*Rtree, []Point) {
+	rt := NewTree(2, 25, 50, randomRects(100000, 40)...)
+	rnd := rand.New(rand.NewSource(41))
+	points := make([]Point, 1000)
+	for i := range points {
+		points[i] = Point{rnd.Float64() * 100, rnd.Float64() * 100}
+	}
+	return rt, points
+}
+
+func BenchmarkNearestNeighbor(b *testing.B) {
+	rt, points := benchmarkTree()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// a new point for every query, so that escaping points show up as
+		// allocations
+		q := points[i%len(points)]
+		rt.NearestNeighbor(Point{q[0], q[1]})
+	}
+}
+
+func BenchmarkNearestNeighbors(b *testing.B) {
+	rt, points := benchmarkTree()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q := points[i%len(points)]
+		rt.NearestNeighbors(10, Point{q[0], q[1]})
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2012 Daniel Connelly.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtreego
+
+// SearchContained returns all objects whose bounding boxes lie entirely
+// inside bb. Objects touching the boundary of bb are included.
+func (tree *Rtree) SearchContained(bb Rect, filters ...Filter) []Spatial {
+	match := func(r, bb Rect) bool {
+		return bb.containsRect(r)
+	}
+	return tree.searchImages(bb, overlaps, match, filters)
+}
+
+// SearchContaining returns all objects whose bounding boxes entirely contain
+// bb. Objects whose boundary touches bb from the inside are included.
+func (tree *Rtree) SearchContaining(bb Rect, filters ...Filter) []Spatial {
+	// a node can only have children containing bb if it contains bb itself
+	contains := func(r, bb Rect) bool {
+		return r.containsRect(bb)
+	}
+	return tree.searchImages(bb, contains, contains, filters)
+}
+
+// search appends to results all objects in the subtree of n whose bounding
+// boxes satisfy match, only descending into entries that satisfy descend.
+// It returns whether a filter aborted the search.
+func (tree *Rtree) search(results []Spatial, n *node, descend, match func(Rect) bool, filters []Filter) ([]Spatial, bool) {
+	var abort bool
+	for _, e := range n.entries {
+		if !n.leaf {
+			if descend(e.bb) {
+				results, abort = tree.search(results, e.child, descend, match, filters)
+			}
+		} else if match(e.bb) {
+			var refuse bool
+			refuse, abort = applyFilters(results, e.obj, filters)
+			if !refuse {
+				results = append(results, e.obj)
+			}
+		}
+
+		if abort {
+			break
+		}
+	}
+	return results, abort
+}
+
+// SearchPoint returns all objects whose bounding boxes contain p. Points on
+// the boundary of a bounding box are considered to be contained in it.
+func (tree *Rtree) SearchPoint(p Point, filters ...Filter) []Spatial {
+	if tree.periods != nil {
+		contains := func(r, img Rect) bool {
+			return r.containsRect(img)
+		}
+		return tree.searchImages(Rect{p: p, q: p}, contains, contains, filters)
+	}
+
+	contains := func(r Rect) bool {
+		return r.containsPoint(p)
+	}
+	results, _ := tree.search([]Spatial{}, tree.root, contains, contains, filters)
+	return results
+}
+
+// SearchWithinDistance returns all objects whose bounding boxes are at most
+// radius away from p in the tree's metric, that is, all objects whose bounding
+// boxes intersect the ball of the given radius around p.
+func (tree *Rtree) SearchWithinDistance(p Point, radius float64, filters ...Filter) []Spatial {
+	if radius < 0 {
+		return []Spatial{}
+	}
+
+	key := tree.distKey(radius)
+	within := func(r Rect) bool {
+		return tree.minDist(p, r) <= key
+	}
+	results, _ := tree.search([]Spatial{}, tree.root, within, within, filters)
+	return results
+}
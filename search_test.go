@@ -0,0 +1,195 @@
+package rtreego
+
+import (
+	"testing"
+)
+
+func TestSearchContained(t *testing.T) {
+	rects := []Rect{
+		mustRect(Point{0, 0}, []float64{2, 2}),
+		mustRect(Point{1, 1}, []float64{1, 1}),
+		mustRect(Point{3, 3}, []float64{1, 1}),
+		mustRect(Point{4, 1}, []float64{2, 1}),
+		mustRect(Point{5, 5}, []float64{1, 1}),
+		mustRect(Point{0, 4}, []float64{1, 2}),
+		mustRect(Point{-1, -1}, []float64{8, 1}),
+	}
+	things := []Spatial{}
+	for i := range rects {
+		things = append(things, &rects[i])
+	}
+
+	for _, tc := range tests(2, 2, 3, things...) {
+		t.Run(tc.name, func(t *testing.T) {
+			rt := tc.build()
+
+			bb := mustRect(Point{0, 0}, []float64{5, 5})
+			expected := []Spatial{things[0], things[1], things[2]}
+			results := rt.SearchContained(bb)
+			if len(results) != len(expected) {
+				t.Fatalf("SearchContained(%v) = %v, expected %v", bb, results, expected)
+			}
+			ensureDisorderedSubset(t, results, expected)
+
+			results = rt.SearchContained(bb, LimitFilter(2))
+			if len(results) != 2 {
+				t.Errorf("SearchContained with LimitFilter(2) returned %d results", len(results))
+			}
+			ensureDisorderedSubset(t, results, expected)
+
+			bb = mustRect(Point{10, 10}, []float64{1, 1})
+			if results := rt.SearchContained(bb); len(results) != 0 {
+				t.Errorf("SearchContained(%v) = %v, expected no results", bb, results)
+			}
+		})
+	}
+}
+
+func TestSearchContaining(t *testing.T) {
+	rects := []Rect{
+		mustRect(Point{0, 0}, []float64{4, 4}),
+		mustRect(Point{1, 1}, []float64{1, 1}),
+		mustRect(Point{1, 1}, []float64{2, 2}),
+		mustRect(Point{2, 2}, []float64{3, 3}),
+		mustRect(Point{5, 5}, []float64{1, 1}),
+		mustRect(Point{-5, -5}, []float64{20, 20}),
+		mustRect(Point{1.5, 0}, []float64{1, 10}),
+	}
+	things := []Spatial{}
+	for i := range rects {
+		things = append(things, &rects[i])
+	}
+
+	for _, tc := range tests(2, 2, 3, things...) {
+		t.Run(tc.name, func(t *testing.T) {
+			rt := tc.build()
+
+			bb := mustRect(Point{1.5, 1.5}, []float64{1, 1})
+			expected := []Spatial{things[0], things[2], things[5], things[6]}
+			results := rt.SearchContaining(bb)
+			if len(results) != len(expected) {
+				t.Fatalf("SearchContaining(%v) = %v, expected %v", bb, results, expected)
+			}
+			ensureDisorderedSubset(t, results, expected)
+
+			// an object equal to bb contains it
+			results = rt.SearchContaining(rects[1])
+			if !contains(things[1], results) {
+				t.Errorf("SearchContaining(%v) = %v, expected it to contain itself", rects[1], results)
+			}
+
+			results = rt.SearchContaining(bb, func(results []Spatial, obj Spatial) (bool, bool) {
+				return obj == things[5], false
+			})
+			if len(results) != 3 || contains(things[5], results) {
+				t.Errorf("SearchContaining with filter = %v, expected filtered results", results)
+			}
+		})
+	}
+}
+
+func TestSearchContainedBoundary(t *testing.T) {
+	// a degenerate object on the boundary of the query is contained, even
+	// though it does not intersect it
+	edge := mustRect(Point{0, 5}, []float64{1, 1})
+	edge.q[1] = edge.p[1]
+	rt := NewTree(2, 1, 2, &edge)
+
+	bb := mustRect(Point{0, 0}, []float64{5, 5})
+	if results := rt.SearchContained(bb); len(results) != 1 {
+		t.Errorf("SearchContained(%v) = %v, expected %v", bb, results, edge)
+	}
+}
+
+func TestSearchPoint(t *testing.T) {
+	rects := []Rect{
+		mustRect(Point{0, 0}, []float64{2, 2}),
+		mustRect(Point{2, 0}, []float64{2, 2}),
+		mustRect(Point{0, 2}, []float64{2, 2}),
+		mustRect(Point{2, 2}, []float64{2, 2}),
+		mustRect(Point{5, 5}, []float64{1, 1}),
+		mustRect(Point{-10, -10}, []float64{20, 20}),
+	}
+	things := []Spatial{}
+	for i := range rects {
+		things = append(things, &rects[i])
+	}
+
+	for _, tc := range tests(2, 2, 3, things...) {
+		t.Run(tc.name, func(t *testing.T) {
+			rt := tc.build()
+
+			// a point on a shared corner is covered by all adjacent zones
+			results := rt.SearchPoint(Point{2, 2})
+			if len(results) != 5 || contains(things[4], results) {
+				t.Errorf("SearchPoint(2, 2) = %v, expected all but %v", results, things[4])
+			}
+
+			results = rt.SearchPoint(Point{1, 3})
+			expected := []Spatial{things[2], things[5]}
+			if len(results) != len(expected) {
+				t.Errorf("SearchPoint(1, 3) = %v, expected %v", results, expected)
+			}
+			ensureDisorderedSubset(t, results, expected)
+
+			results = rt.SearchPoint(Point{2, 2}, LimitFilter(1))
+			if len(results) != 1 {
+				t.Errorf("SearchPoint with LimitFilter(1) returned %d results", len(results))
+			}
+
+			if results := rt.SearchPoint(Point{20, 20}); len(results) != 0 {
+				t.Errorf("SearchPoint(20, 20) = %v, expected no results", results)
+			}
+		})
+	}
+}
+
+func TestSearchWithinDistance(t *testing.T) {
+	rects := []Rect{
+		mustRect(Point{1, 1}, []float64{1, 1}),
+		mustRect(Point{-7, -7}, []float64{1, 1}),
+		mustRect(Point{1, 3}, []float64{1, 1}),
+		mustRect(Point{7, 7}, []float64{1, 1}),
+		mustRect(Point{10, 2}, []float64{1, 1}),
+		mustRect(Point{3, 3}, []float64{1, 1}),
+		mustRect(Point{-3, 0}, []float64{1, 1}),
+	}
+	things := []Spatial{}
+	for i := range rects {
+		things = append(things, &rects[i])
+	}
+
+	for _, tc := range tests(2, 2, 3, things...) {
+		t.Run(tc.name, func(t *testing.T) {
+			rt := tc.build()
+			p := Point{0.5, 0.5}
+
+			for _, radius := range []float64{0, 0.5, 1, 2.5, 3, 5, 10, 20} {
+				var expected []Spatial
+				for _, thing := range things {
+					if p.minDist(thing.Bounds()) <= radius*radius {
+						expected = append(expected, thing)
+					}
+				}
+
+				results := rt.SearchWithinDistance(p, radius)
+				if len(results) != len(expected) {
+					t.Errorf("SearchWithinDistance(%v, %v) = %v, expected %v", p, radius, results, expected)
+				}
+				ensureDisorderedSubset(t, results, expected)
+			}
+
+			// the object at (-3, 0) is exactly 2.5 away
+			if results := rt.SearchWithinDistance(p, 2.5); !contains(things[6], results) {
+				t.Errorf("expected SearchWithinDistance to include objects at exactly the radius")
+			}
+
+			if results := rt.SearchWithinDistance(p, 20, LimitFilter(2)); len(results) != 2 {
+				t.Errorf("SearchWithinDistance with LimitFilter(2) returned %d results", len(results))
+			}
+			if results := rt.SearchWithinDistance(p, -1); len(results) != 0 {
+				t.Errorf("SearchWithinDistance with a negative radius returned %v", results)
+			}
+		})
+	}
+}
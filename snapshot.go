@@ -0,0 +1,88 @@
+// Copyright 2012 Daniel Connelly.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtreego
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrReadOnly is returned by Merge, and the other methods modifying a tree
+// panic with it, when called on a snapshot.
+var ErrReadOnly = errors.New("rtreego: snapshot is read-only")
+
+// generations is the last generation handed out by nextGeneration.
+var generations uint64
+
+// nextGeneration returns a generation which no tree or node has used before.
+func nextGeneration() uint64 {
+	return atomic.AddUint64(&generations, 1)
+}
+
+// Snapshot returns a read-only view of the tree in its current state, which
+// is not affected by later modifications of the tree. It takes constant time:
+// the snapshot shares all nodes with the tree, which from then on copies the
+// nodes along the path to the root before modifying them.
+//
+// Queries on a snapshot may run concurrently with each other and with
+// modifications of the tree, as long as the tree itself is not used
+// concurrently, e.g. by taking snapshots of a SyncRtree. Modifying a snapshot
+// panics with ErrReadOnly.
+func (tree *Rtree) Snapshot() *Rtree {
+	if tree.readOnly {
+		return tree
+	}
+	snap := *tree
+	snap.deleted = nil
+	snap.readOnly = true
+
+	// the nodes of the current generation now belong to the snapshot
+	tree.gen = nextGeneration()
+	return &snap
+}
+
+// checkWritable panics if the tree is a snapshot.
+func (tree *Rtree) checkWritable() {
+	if tree.readOnly {
+		panic(ErrReadOnly)
+	}
+}
+
+// own returns a version of the node n reachable from the root which the tree
+// may modify in place. Nodes of an older generation may be shared with
+// snapshots, so they are copied along with their ancestors, and the copies
+// replace them in the tree.
+func (tree *Rtree) own(n *node) *node {
+	if n.gen == tree.gen {
+		return n
+	}
+
+	c := &node{
+		parent:  n.parent,
+		entries: make([]entry, len(n.entries), tree.MaxChildren+1),
+		level:   n.level,
+		leaf:    n.leaf,
+		gen:     tree.gen,
+	}
+	copy(c.entries, n.entries)
+	for _, e := range c.entries {
+		if e.child != nil {
+			e.child.parent = c
+		}
+	}
+
+	if n == tree.root {
+		tree.root = c
+		return c
+	}
+	c.parent = tree.own(n.parent)
+	for i := range c.parent.entries {
+		if c.parent.entries[i].child == n {
+			c.parent.entries[i].child = c
+			break
+		}
+	}
+	return c
+}
@@ -0,0 +1,162 @@
+package rtreego
+
+import (
+	"sync"
+	"testing"
+)
+
+// ensureUnchanged checks that the snapshot still has the given objects and
+// bounding boxes, in the same order.
+func ensureUnchanged(t *testing.T, snap *Rtree, objs []Spatial, bbs []Rect) {
+	t.Helper()
+	actual := snap.root.objects(nil)
+	if len(actual) != len(objs) || snap.Size() != len(objs) {
+		t.Fatalf("snapshot has %d objects (size %d), expected %d", len(actual), snap.Size(), len(objs))
+	}
+	for i := range objs {
+		if actual[i] != objs[i] {
+			t.Fatalf("snapshot object %d changed: %v != %v", i, actual[i], objs[i])
+		}
+	}
+	actualBBs := snap.GetAllBoundingBoxes()
+	if len(actualBBs) != len(bbs) {
+		t.Fatalf("snapshot has %d bounding boxes, expected %d", len(actualBBs), len(bbs))
+	}
+	for i := range bbs {
+		if !rectEq(actualBBs[i], bbs[i]) {
+			t.Fatalf("snapshot bounding box %d changed: %v != %v", i, actualBBs[i], bbs[i])
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	things := randomRects(600, 23)
+	for name, opts := range map[string]Options{
+		"guttman": {},
+		"rstar":   {InsertMode: RStarInsert},
+		"hilbert": {
+			InsertMode:    HilbertInsert,
+			HilbertBounds: mustRect(Point{0, 0}, []float64{100, 100}),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rt := NewTreeWithOptions(2, 3, 6, opts, things[:300]...)
+			snap := rt.Snapshot()
+			objs, bbs := snap.root.objects(nil), snap.GetAllBoundingBoxes()
+
+			for i, thing := range things[300:] {
+				rt.Insert(thing)
+				if !rt.Delete(things[i]) {
+					t.Fatalf("failed to delete %v", things[i])
+				}
+				if i == 100 {
+					// snapshots of snapshots are unaffected as well
+					second := rt.Snapshot()
+					secondObjs, secondBBs := second.root.objects(nil), second.GetAllBoundingBoxes()
+					defer ensureUnchanged(t, second, secondObjs, secondBBs)
+				}
+			}
+			rt.InsertBulk(things[:100])
+
+			verify(t, rt)
+			if rt.Size() != 400 {
+				t.Errorf("expected size 400, got %d", rt.Size())
+			}
+			everything := mustRect(Point{-1, -1}, []float64{200, 200})
+			ensureDisorderedSubset(t, rt.SearchIntersect(everything), append(things[300:], things[:100]...))
+
+			ensureUnchanged(t, snap, objs, bbs)
+			ensureDisorderedSubset(t, snap.SearchIntersect(everything), things[:300])
+			if nn := snap.NearestNeighbor(things[5].Bounds().p); nn != things[5] {
+				t.Errorf("expected snapshot NearestNeighbor to return %v, got %v", things[5], nn)
+			}
+		})
+	}
+}
+
+func TestSnapshotConcurrent(t *testing.T) {
+	things := randomRects(400, 24)
+	st := NewSyncTree(NewTree(2, 3, 6, things[:200]...))
+	snap := st.Snapshot()
+	objs, bbs := snap.root.objects(nil), snap.GetAllBoundingBoxes()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i, thing := range things[200:] {
+			st.Insert(thing)
+			st.Delete(things[i])
+		}
+	}()
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, thing := range things[:200] {
+				if !contains(thing, snap.SearchIntersect(thing.Bounds())) {
+					t.Errorf("snapshot SearchIntersect failed to find %v", thing)
+				}
+				snap.NearestNeighbors(3, thing.Bounds().p)
+			}
+		}()
+	}
+	wg.Wait()
+
+	ensureUnchanged(t, snap, objs, bbs)
+	st.View(func(rt *Rtree) {
+		verify(t, rt)
+	})
+}
+
+func TestSnapshotReadOnly(t *testing.T) {
+	things := randomRects(20, 25)
+	rt := NewTree(2, 3, 6, things...)
+	snap := rt.Snapshot()
+
+	for name, modify := range map[string]func(){
+		"Insert":     func() { snap.Insert(things[0]) },
+		"Delete":     func() { snap.Delete(things[0]) },
+		"InsertBulk": func() { snap.InsertBulk(things) },
+	} {
+		func() {
+			defer func() {
+				if err := recover(); err != ErrReadOnly {
+					t.Errorf("expected %s on snapshot to panic with ErrReadOnly, got %v", name, err)
+				}
+			}()
+			modify()
+		}()
+	}
+
+	if err := rt.Merge(snap); err != ErrReadOnly {
+		t.Errorf("expected Merge of snapshot to fail with ErrReadOnly, got %v", err)
+	}
+	if err := snap.Merge(NewTree(2, 3, 6)); err != ErrReadOnly {
+		t.Errorf("expected Merge into snapshot to fail with ErrReadOnly, got %v", err)
+	}
+	if snap.Size() != len(things) {
+		t.Errorf("expected snapshot size %d, got %d", len(things), snap.Size())
+	}
+}
+
+func TestSnapshotMerge(t *testing.T) {
+	things := randomRects(100, 26)
+	rt := NewTree(2, 3, 6, things[:50]...)
+	other := NewTree(2, 3, 6, things[50:]...)
+	snap := other.Snapshot()
+	objs, bbs := snap.root.objects(nil), snap.GetAllBoundingBoxes()
+
+	if err := rt.Merge(other); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	for _, thing := range things[50:75] {
+		if !rt.Delete(thing) {
+			t.Fatalf("failed to delete %v", thing)
+		}
+	}
+	rt.Insert(things[60])
+
+	verify(t, rt)
+	ensureUnchanged(t, snap, objs, bbs)
+}
@@ -0,0 +1,342 @@
+// Copyright 2012 Daniel Connelly.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtreego
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// SplitStrategy decides how the entries of an overflowing node are divided
+// between the node and its new sibling.
+type SplitStrategy interface {
+	// Split partitions bbs, the bounding boxes of the entries of an
+	// overflowing node, into two non-empty groups and returns the indices of
+	// the entries in each group. Every index must be returned exactly once,
+	// and both groups should hold at least minGroupSize entries.
+	Split(bbs []Rect, minGroupSize int) (left, right []int)
+}
+
+// nodeSplitter is implemented by the built-in split strategies, which split
+// nodes in place instead of going through the index-based Split.
+type nodeSplitter interface {
+	splitNode(n *node, minGroupSize int) (left, right *node)
+}
+
+// LinearSplit is Guttman's linear-cost split. It is the fastest strategy but
+// produces the most overlap between nodes.
+type LinearSplit struct{}
+
+// Split implements SplitStrategy.
+func (s LinearSplit) Split(bbs []Rect, minGroupSize int) (left, right []int) {
+	return splitRects(s, bbs, minGroupSize)
+}
+
+func (LinearSplit) splitNode(n *node, minGroupSize int) (left, right *node) {
+	return n.splitLinear(minGroupSize)
+}
+
+// QuadraticSplit is Guttman's quadratic-cost split, which is the default.
+type QuadraticSplit struct{}
+
+// Split implements SplitStrategy.
+func (s QuadraticSplit) Split(bbs []Rect, minGroupSize int) (left, right []int) {
+	return splitRects(s, bbs, minGroupSize)
+}
+
+func (QuadraticSplit) splitNode(n *node, minGroupSize int) (left, right *node) {
+	return n.split(minGroupSize)
+}
+
+// RStarSplit is the margin-based topological split of the R*-tree.
+type RStarSplit struct{}
+
+// Split implements SplitStrategy.
+func (s RStarSplit) Split(bbs []Rect, minGroupSize int) (left, right []int) {
+	return splitRects(s, bbs, minGroupSize)
+}
+
+func (RStarSplit) splitNode(n *node, minGroupSize int) (left, right *node) {
+	return n.splitRStar(minGroupSize)
+}
+
+// AngTanSplit is the linear-cost split by Ang and Tan, which distributes the
+// entries according to the node boundary they are closest to.
+type AngTanSplit struct{}
+
+// Split implements SplitStrategy.
+func (s AngTanSplit) Split(bbs []Rect, minGroupSize int) (left, right []int) {
+	return splitRects(s, bbs, minGroupSize)
+}
+
+func (AngTanSplit) splitNode(n *node, minGroupSize int) (left, right *node) {
+	return n.splitAngTan(minGroupSize)
+}
+
+// splitNode splits the overflowing node n using the tree's split strategy.
+func (tree *Rtree) splitNode(n *node) (left, right *node) {
+	strategy := tree.splitStrategy
+	if strategy == nil {
+		if tree.insertMode == RStarInsert {
+			strategy = RStarSplit{}
+		} else {
+			strategy = QuadraticSplit{}
+		}
+	}
+
+	if s, ok := strategy.(nodeSplitter); ok {
+		return s.splitNode(n, tree.MinChildren)
+	}
+
+	bbs := make([]Rect, len(n.entries))
+	for i, e := range n.entries {
+		bbs[i] = e.bb
+	}
+	l, r := strategy.Split(bbs, tree.MinChildren)
+	if !isPartition(len(bbs), l, r) {
+		panic(fmt.Errorf("rtreego: split strategy returned an invalid partition"))
+	}
+	return n.splitIndices(l, r)
+}
+
+// splitIndices splits a node into two groups made of the entries with the
+// given indices, re-using n as the left node.
+func (n *node) splitIndices(l, r []int) (left, right *node) {
+	entries := n.entries
+	left = n
+	left.entries = make([]entry, 0, len(l))
+	for _, i := range l {
+		assign(entries[i], left)
+	}
+	right = &node{
+		parent:  n.parent,
+		leaf:    n.leaf,
+		level:   n.level,
+		entries: make([]entry, 0, len(r)),
+		gen:     n.gen,
+	}
+	for _, i := range r {
+		assign(entries[i], right)
+	}
+	return
+}
+
+// isPartition checks that l and r are non-empty and together contain every
+// index in [0, n) exactly once.
+func isPartition(n int, l, r []int) bool {
+	if len(l) == 0 || len(r) == 0 || len(l)+len(r) != n {
+		return false
+	}
+	seen := make([]bool, n)
+	for _, group := range [][]int{l, r} {
+		for _, i := range group {
+			if i < 0 || i >= n || seen[i] {
+				return false
+			}
+			seen[i] = true
+		}
+	}
+	return true
+}
+
+// indexedRect is a placeholder object used to recover the indices of the
+// bounding boxes passed to the Split method of the built-in strategies.
+type indexedRect struct {
+	bb    Rect
+	index int
+}
+
+func (r indexedRect) Bounds() Rect {
+	return r.bb
+}
+
+// splitRects implements the index-based Split method on top of the node
+// based split of a built-in strategy.
+func splitRects(s nodeSplitter, bbs []Rect, minGroupSize int) (left, right []int) {
+	n := &node{leaf: true, entries: make([]entry, len(bbs))}
+	for i, bb := range bbs {
+		n.entries[i] = entry{bb: bb, obj: indexedRect{bb, i}}
+	}
+
+	l, r := s.splitNode(n, minGroupSize)
+	for _, e := range l.entries {
+		left = append(left, e.obj.(indexedRect).index)
+	}
+	for _, e := range r.entries {
+		right = append(right, e.obj.(indexedRect).index)
+	}
+	return
+}
+
+// clampGroupSize restricts the minimum group size of a split of count
+// entries so that at least one valid distribution exists.
+func clampGroupSize(minGroupSize, count int) int {
+	if minGroupSize < 1 {
+		minGroupSize = 1
+	}
+	if 2*minGroupSize > count {
+		minGroupSize = count / 2
+	}
+	return minGroupSize
+}
+
+// linearPickSeeds chooses two child entries of n to start a linear split:
+// the pair with the greatest normalized separation along any dimension.
+// The returned indices are ordered.
+func (n *node) linearPickSeeds() (int, int) {
+	left, right := 0, 1
+	maxSeparation := math.Inf(-1)
+	dim := len(n.entries[0].bb.p)
+	for d := 0; d < dim; d++ {
+		// find the entry with the highest low side and, among the others,
+		// the entry with the lowest high side
+		highestLow := 0
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for i, e := range n.entries {
+			if e.bb.p[d] > n.entries[highestLow].bb.p[d] {
+				highestLow = i
+			}
+			lo = math.Min(lo, e.bb.p[d])
+			hi = math.Max(hi, e.bb.q[d])
+		}
+		lowestHigh := -1
+		for i, e := range n.entries {
+			if i != highestLow && (lowestHigh < 0 || e.bb.q[d] < n.entries[lowestHigh].bb.q[d]) {
+				lowestHigh = i
+			}
+		}
+
+		separation := n.entries[highestLow].bb.p[d] - n.entries[lowestHigh].bb.q[d]
+		if width := hi - lo; width > 0 {
+			separation /= width
+		}
+		if separation > maxSeparation {
+			maxSeparation = separation
+			left, right = lowestHigh, highestLow
+		}
+	}
+
+	if left > right {
+		left, right = right, left
+	}
+	return left, right
+}
+
+// splitLinear splits a node using Guttman's linear-cost algorithm: after
+// picking the seeds, the remaining entries are assigned in their original
+// order to the group needing the least enlargement. The bounding boxes of
+// the groups are maintained incrementally to keep the cost linear.
+func (n *node) splitLinear(minGroupSize int) (left, right *node) {
+	l, r := n.linearPickSeeds()
+	groups := [2][]int{{l}, {r}}
+	bbs := [2]Rect{n.entries[l].bb, n.entries[r].bb}
+
+	remaining := len(n.entries) - 2
+	for i, e := range n.entries {
+		if i == l || i == r {
+			continue
+		}
+
+		var g int
+		if remaining+len(groups[0]) <= minGroupSize {
+			g = 0
+		} else if remaining+len(groups[1]) <= minGroupSize {
+			g = 1
+		} else {
+			g = chooseGroup(e.bb, bbs, len(groups[0]), len(groups[1]))
+		}
+		groups[g] = append(groups[g], i)
+		bbs[g] = boundingBox(bbs[g], e.bb)
+		remaining--
+	}
+
+	return n.splitIndices(groups[0], groups[1])
+}
+
+// chooseGroup returns the index of the group that should receive an entry
+// with the given bounding box, using the same criteria as assignGroup.
+func chooseGroup(bb Rect, groups [2]Rect, leftCount, rightCount int) int {
+	leftSize, rightSize := groups[0].Size(), groups[1].Size()
+	leftDiff := boundingBox(groups[0], bb).Size() - leftSize
+	rightDiff := boundingBox(groups[1], bb).Size() - rightSize
+	switch {
+	case leftDiff < rightDiff:
+		return 0
+	case leftDiff > rightDiff:
+		return 1
+	case leftSize < rightSize:
+		return 0
+	case leftSize > rightSize:
+		return 1
+	case leftCount <= rightCount:
+		return 0
+	}
+	return 1
+}
+
+// splitAngTan splits a node by assigning every entry to the group of the
+// node boundary it is closest to. The dimension giving the most even
+// distribution is used, breaking ties by least overlap and then least total
+// area.
+//
+// Implemented per "New Linear Node Splitting Algorithm for R-trees" by
+// C. H. Ang and T. C. Tan, SSD, p. 339-349, 1997.
+func (n *node) splitAngTan(minGroupSize int) (left, right *node) {
+	count := len(n.entries)
+	m := clampGroupSize(minGroupSize, count)
+	bb := n.computeBoundingBox()
+
+	var best []int
+	bestSplit, bestBalance := 0, count
+	minOverlap, minSize := math.MaxFloat64, math.MaxFloat64
+
+	order := make([]int, count)
+	keys := make([]float64, count)
+	for d := range bb.p {
+		// entries with a negative key are closer to the lower boundary
+		for i, e := range n.entries {
+			order[i] = i
+			keys[i] = (e.bb.p[d] - bb.p[d]) - (bb.q[d] - e.bb.q[d])
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			return keys[order[i]] < keys[order[j]]
+		})
+
+		split := 0
+		for split < count && keys[order[split]] < 0 {
+			split++
+		}
+		if split < m {
+			split = m
+		} else if split > count-m {
+			split = count - m
+		}
+
+		balance := split
+		if count-split > balance {
+			balance = count - split
+		}
+		lbb, rbb := n.entries[order[0]].bb, n.entries[order[count-1]].bb
+		for _, i := range order[:split] {
+			lbb = boundingBox(lbb, n.entries[i].bb)
+		}
+		for _, i := range order[split:] {
+			rbb = boundingBox(rbb, n.entries[i].bb)
+		}
+		overlap := overlapSize(lbb, rbb)
+		size := lbb.Size() + rbb.Size()
+
+		if balance < bestBalance ||
+			(balance == bestBalance && (overlap < minOverlap ||
+				(overlap == minOverlap && size < minSize))) {
+			best = append(best[:0], order...)
+			bestSplit, bestBalance = split, balance
+			minOverlap, minSize = overlap, size
+		}
+	}
+
+	return n.splitIndices(best[:bestSplit], best[bestSplit:])
+}
@@ -0,0 +1,171 @@
+package rtreego
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+var splitStrategies = []SplitStrategy{
+	LinearSplit{},
+	QuadraticSplit{},
+	RStarSplit{},
+	AngTanSplit{},
+}
+
+func TestSplitStrategiesInsert(t *testing.T) {
+	things := randomRects(300, 3)
+	for _, strategy := range splitStrategies {
+		for _, mode := range []InsertMode{GuttmanInsert, RStarInsert} {
+			t.Run(fmt.Sprintf("%T-%d", strategy, mode), func(t *testing.T) {
+				rt := NewTreeWithOptions(2, 2, 6, Options{InsertMode: mode, SplitStrategy: strategy})
+				for _, thing := range things {
+					rt.Insert(thing)
+				}
+				verify(t, rt)
+				if rt.Size() != len(things) {
+					t.Errorf("Insert failed to insert, got size %d", rt.Size())
+				}
+				for _, thing := range things {
+					if !contains(thing, rt.SearchIntersect(thing.Bounds())) {
+						t.Fatalf("SearchIntersect failed to find %v", thing)
+					}
+				}
+				for _, thing := range things[:150] {
+					if !rt.Delete(thing) {
+						t.Fatalf("Delete failed to delete %v", thing)
+					}
+				}
+				verify(t, rt)
+			})
+		}
+	}
+}
+
+func TestSplitStrategiesMinGroupSize(t *testing.T) {
+	bbs := []Rect{
+		mustRect(Point{0, 0}, []float64{1, 1}),
+		mustRect(Point{0, 1}, []float64{1, 1}),
+		mustRect(Point{0, 2}, []float64{1, 1}),
+		mustRect(Point{0, 3}, []float64{1, 1}),
+		mustRect(Point{-50, -50}, []float64{1, 1}),
+	}
+	for _, strategy := range splitStrategies {
+		left, right := strategy.Split(bbs, 2)
+		if !isPartition(len(bbs), left, right) {
+			t.Errorf("%T: expected a partition, got %v and %v", strategy, left, right)
+		}
+		if len(left) < 2 || len(right) < 2 {
+			t.Errorf("%T: expected groups of at least 2 entries, got %v and %v", strategy, left, right)
+		}
+	}
+}
+
+func TestLinearPickSeeds(t *testing.T) {
+	entry1 := entry{bb: mustRect(Point{1, 1}, []float64{1, 1})}
+	entry2 := entry{bb: mustRect(Point{10, 0}, []float64{1, 1})}
+	entry3 := entry{bb: mustRect(Point{-1, -1}, []float64{1, 2})}
+	n := node{entries: []entry{entry1, entry2, entry3}}
+	left, right := n.linearPickSeeds()
+	if left != 1 || right != 2 {
+		t.Errorf("expected entries 1, 2, got %d, %d", left, right)
+	}
+}
+
+func TestSplitAngTan(t *testing.T) {
+	entries := []entry{
+		{bb: mustRect(Point{0, 0}, []float64{1, 1})},
+		{bb: mustRect(Point{9, 9}, []float64{1, 1})},
+		{bb: mustRect(Point{0, 9}, []float64{1, 1})},
+		{bb: mustRect(Point{9, 0}, []float64{1, 1})},
+		{bb: mustRect(Point{0, 4}, []float64{1, 1})},
+		{bb: mustRect(Point{1, 5}, []float64{1, 1})},
+	}
+	n := &node{entries: entries}
+
+	// the split along the second dimension is the more even one
+	l, r := n.splitAngTan(1)
+	expLeft := mustRect(Point{0, 0}, []float64{10, 5})
+	expRight := mustRect(Point{0, 5}, []float64{10, 5})
+	if lbb, rbb := l.computeBoundingBox(), r.computeBoundingBox(); !rectEq(lbb, expLeft) || !rectEq(rbb, expRight) {
+		t.Errorf("expected split into %v and %v, got %v and %v", expLeft, expRight, lbb, rbb)
+	}
+}
+
+// evenOddSplit is a custom strategy used to test the index-based interface.
+type evenOddSplit struct{}
+
+func (evenOddSplit) Split(bbs []Rect, minGroupSize int) (left, right []int) {
+	for i := range bbs {
+		if i%2 == 0 {
+			left = append(left, i)
+		} else {
+			right = append(right, i)
+		}
+	}
+	return
+}
+
+func TestCustomSplitStrategy(t *testing.T) {
+	entries := []entry{
+		{bb: mustRect(Point{0, 0}, []float64{1, 1})},
+		{bb: mustRect(Point{1, 0}, []float64{1, 1})},
+		{bb: mustRect(Point{2, 0}, []float64{1, 1})},
+		{bb: mustRect(Point{3, 0}, []float64{1, 1})},
+	}
+	rt := NewTreeWithOptions(2, 1, 3, Options{SplitStrategy: evenOddSplit{}})
+	n := &node{entries: append([]entry{}, entries...), leaf: true, level: 1}
+
+	l, r := rt.splitNode(n)
+	if len(l.entries) != 2 || !entryEq(l.entries[0], entries[0]) || !entryEq(l.entries[1], entries[2]) {
+		t.Errorf("expected left group to hold the even entries")
+	}
+	if len(r.entries) != 2 || !entryEq(r.entries[0], entries[1]) || !entryEq(r.entries[1], entries[3]) {
+		t.Errorf("expected right group to hold the odd entries")
+	}
+
+	things := randomRects(50, 4)
+	for _, thing := range things {
+		rt.Insert(thing)
+	}
+	verify(t, rt)
+}
+
+// brokenSplit is a custom strategy that does not partition the entries.
+type brokenSplit struct{}
+
+func (brokenSplit) Split(bbs []Rect, minGroupSize int) (left, right []int) {
+	return []int{0}, []int{0}
+}
+
+func TestCustomSplitStrategyInvalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected splitNode to panic on an invalid partition")
+		}
+	}()
+
+	rt := NewTreeWithOptions(2, 1, 1, Options{SplitStrategy: brokenSplit{}})
+	n := &node{entries: []entry{
+		{bb: mustRect(Point{0, 0}, []float64{1, 1})},
+		{bb: mustRect(Point{1, 0}, []float64{1, 1})},
+	}}
+	rt.splitNode(n)
+}
+
+func TestSplitRectsIndices(t *testing.T) {
+	bbs := []Rect{
+		mustRect(Point{0, 0}, []float64{1, 1}),
+		mustRect(Point{20, 0}, []float64{1, 1}),
+		mustRect(Point{1, 0}, []float64{1, 1}),
+		mustRect(Point{21, 0}, []float64{1, 1}),
+	}
+	for _, strategy := range splitStrategies {
+		left, right := strategy.Split(bbs, 1)
+		sort.Ints(left)
+		sort.Ints(right)
+		if fmt.Sprint(left, right) != "[0 2] [1 3]" && fmt.Sprint(left, right) != "[1 3] [0 2]" {
+			t.Errorf("%T: expected the nearby boxes to be grouped, got %v and %v", strategy, left, right)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2012 Daniel Connelly.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtreego
+
+import (
+	"context"
+	"sync"
+)
+
+// SyncRtree wraps an Rtree for concurrent use by multiple goroutines. Queries
+// hold a read lock, so that any number of them can run in parallel, while
+// insertions and deletions hold the write lock and run exclusively.
+type SyncRtree struct {
+	mu   sync.RWMutex
+	tree *Rtree
+}
+
+// NewSyncTree returns a SyncRtree wrapping tree. The tree must not be used
+// directly afterwards, except within View and Update.
+func NewSyncTree(tree *Rtree) *SyncRtree {
+	return &SyncRtree{tree: tree}
+}
+
+// View calls fn with the wrapped tree while holding the read lock. It gives
+// access to the queries which outlive a single call, such as NearestIterator
+// and Join. fn must not modify the tree or keep it after returning.
+func (t *SyncRtree) View(fn func(tree *Rtree)) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	fn(t.tree)
+}
+
+// Update calls fn with the wrapped tree while holding the write lock, so that
+// several modifications can be applied atomically. fn must not keep the tree
+// after returning.
+func (t *SyncRtree) Update(fn func(tree *Rtree)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fn(t.tree)
+}
+
+// Snapshot returns a read-only view of the tree in its current state, as in
+// Rtree.Snapshot. The snapshot can be queried without any locking while the
+// tree keeps being modified.
+func (t *SyncRtree) Snapshot() *Rtree {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tree.Snapshot()
+}
+
+// Size returns the number of objects currently stored in the tree.
+func (t *SyncRtree) Size() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.Size()
+}
+
+// Depth returns the maximum depth of the tree.
+func (t *SyncRtree) Depth() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.Depth()
+}
+
+// Insert inserts a spatial object into the tree, as in Rtree.Insert.
+func (t *SyncRtree) Insert(obj Spatial) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tree.Insert(obj)
+}
+
+// InsertBulk inserts many spatial objects into the tree at once, as in
+// Rtree.InsertBulk.
+func (t *SyncRtree) InsertBulk(objs []Spatial) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tree.InsertBulk(objs)
+}
+
+// Delete removes an object from the tree, as in Rtree.Delete.
+func (t *SyncRtree) Delete(obj Spatial) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tree.Delete(obj)
+}
+
+// DeleteWithComparator removes an object from the tree using a custom
+// comparator, as in Rtree.DeleteWithComparator.
+func (t *SyncRtree) DeleteWithComparator(obj Spatial, cmp Comparator) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tree.DeleteWithComparator(obj, cmp)
+}
+
+// Merge inserts the objects of other into the tree, as in Rtree.Merge. other
+// must not be modified concurrently.
+func (t *SyncRtree) Merge(other *Rtree) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tree.Merge(other)
+}
+
+// SearchIntersect returns all objects that intersect the specified rectangle,
+// as in Rtree.SearchIntersect.
+func (t *SyncRtree) SearchIntersect(bb Rect, filters ...Filter) []Spatial {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.SearchIntersect(bb, filters...)
+}
+
+// SearchIntersectWithLimit is similar to SearchIntersect, but returns
+// immediately when the first k results are found, as in
+// Rtree.SearchIntersectWithLimit.
+func (t *SyncRtree) SearchIntersectWithLimit(k int, bb Rect) []Spatial {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.SearchIntersectWithLimit(k, bb)
+}
+
+// SearchContained returns all objects contained in bb, as in
+// Rtree.SearchContained.
+func (t *SyncRtree) SearchContained(bb Rect, filters ...Filter) []Spatial {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.SearchContained(bb, filters...)
+}
+
+// SearchContaining returns all objects containing bb, as in
+// Rtree.SearchContaining.
+func (t *SyncRtree) SearchContaining(bb Rect, filters ...Filter) []Spatial {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.SearchContaining(bb, filters...)
+}
+
+// SearchPoint returns all objects containing p, as in Rtree.SearchPoint.
+func (t *SyncRtree) SearchPoint(p Point, filters ...Filter) []Spatial {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.SearchPoint(p, filters...)
+}
+
+// SearchWithinDistance returns all objects within radius of p, as in
+// Rtree.SearchWithinDistance.
+func (t *SyncRtree) SearchWithinDistance(p Point, radius float64, filters ...Filter) []Spatial {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.SearchWithinDistance(p, radius, filters...)
+}
+
+// NearestNeighbor returns the closest object to the specified point, as in
+// Rtree.NearestNeighbor.
+func (t *SyncRtree) NearestNeighbor(p Point) Spatial {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.NearestNeighbor(p)
+}
+
+// NearestNeighbors returns the k nearest neighbors of p, as in
+// Rtree.NearestNeighbors.
+func (t *SyncRtree) NearestNeighbors(k int, p Point, filters ...Filter) []Spatial {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.NearestNeighbors(k, p, filters...)
+}
+
+// NearestNeighborsWithDistances returns the k nearest neighbors of p along
+// with their distances, as in Rtree.NearestNeighborsWithDistances.
+func (t *SyncRtree) NearestNeighborsWithDistances(k int, p Point, filters ...Filter) ([]Spatial, []float64) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.NearestNeighborsWithDistances(k, p, filters...)
+}
+
+// ReverseNearestNeighbors returns the objects which would have p among their
+// k nearest neighbors, as in Rtree.ReverseNearestNeighbors.
+func (t *SyncRtree) ReverseNearestNeighbors(k int, p Point) []Spatial {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.ReverseNearestNeighbors(k, p)
+}
+
+// SelfJoin calls fn for every pair of intersecting objects in the tree, as in
+// Rtree.SelfJoin. The read lock is held while fn is called, so fn must not
+// modify the tree.
+func (t *SyncRtree) SelfJoin(fn func(a, b Spatial) bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	t.tree.SelfJoin(fn)
+}
+
+// GetAllBoundingBoxes returns the bounding boxes of all nodes and objects, as
+// in Rtree.GetAllBoundingBoxes.
+func (t *SyncRtree) GetAllBoundingBoxes() []Rect {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.GetAllBoundingBoxes()
+}
+
+// SearchIntersectBatch runs SearchIntersect for every rectangle in parallel,
+// as in Rtree.SearchIntersectBatch.
+func (t *SyncRtree) SearchIntersectBatch(rects []Rect, workers int) [][]Spatial {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.SearchIntersectBatch(rects, workers)
+}
+
+// NearestNeighborsBatch runs NearestNeighbors for every point in parallel, as
+// in Rtree.NearestNeighborsBatch.
+func (t *SyncRtree) NearestNeighborsBatch(k int, points []Point, workers int) [][]Spatial {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.NearestNeighborsBatch(k, points, workers)
+}
+
+// SearchIntersectContext is like SearchIntersect, but stops once ctx is done,
+// as in Rtree.SearchIntersectContext.
+func (t *SyncRtree) SearchIntersectContext(ctx context.Context, bb Rect, filters ...Filter) ([]Spatial, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.SearchIntersectContext(ctx, bb, filters...)
+}
+
+// NearestNeighborsContext is like NearestNeighbors, but stops once ctx is
+// done, as in Rtree.NearestNeighborsContext.
+func (t *SyncRtree) NearestNeighborsContext(ctx context.Context, k int, p Point, filters ...Filter) ([]Spatial, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.NearestNeighborsContext(ctx, k, p, filters...)
+}
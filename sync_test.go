@@ -0,0 +1,67 @@
+package rtreego
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSyncRtreeConcurrent(t *testing.T) {
+	things := randomRects(400, 21)
+	st := NewSyncTree(NewTree(2, 3, 6, things[:200]...))
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 200 + w; i < len(things); i += 4 {
+				st.Insert(things[i])
+				if !st.Delete(things[i-200]) {
+					t.Errorf("failed to delete %v", things[i-200])
+				}
+			}
+		}(w)
+	}
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				bb := things[i].Bounds()
+				st.SearchIntersect(bb)
+				st.NearestNeighbors(5, bb.p)
+				st.View(func(rt *Rtree) {
+					if _, _, ok := rt.NearestIterator(bb.p).Next(); !ok && rt.Size() > 0 {
+						t.Errorf("expected NearestIterator to return an object")
+					}
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if st.Size() != 200 {
+		t.Errorf("expected size 200, got %d", st.Size())
+	}
+	st.View(func(rt *Rtree) {
+		verify(t, rt)
+	})
+	everything := mustRect(Point{-1, -1}, []float64{200, 200})
+	ensureDisorderedSubset(t, st.SearchIntersect(everything), things[200:])
+}
+
+func TestSyncRtreeUpdate(t *testing.T) {
+	things := randomRects(20, 22)
+	st := NewSyncTree(NewTree(2, 3, 6))
+	st.Update(func(rt *Rtree) {
+		for _, thing := range things {
+			rt.Insert(thing)
+		}
+	})
+	if st.Size() != len(things) {
+		t.Errorf("expected size %d, got %d", len(things), st.Size())
+	}
+	if obj := st.NearestNeighbor(things[3].Bounds().p); obj != things[3] {
+		t.Errorf("expected NearestNeighbor to return %v, got %v", things[3], obj)
+	}
+}